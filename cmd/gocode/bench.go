@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/noriah/go-code/example/bench"
+)
+
+// runBench runs the benchmark cases and writes a markdown table
+func runBench(args []string, out io.Writer) error {
+	var fs = flag.NewFlagSet("bench", flag.ContinueOnError)
+
+	var groups = fs.String("group", "", "comma separated groups to run. empty runs all")
+	var sizes = fs.String("sizes", "", "comma separated input sizes. empty uses the defaults")
+	var quiet = fs.Bool("quiet", false, "don't report progress on stderr")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var parsed []int
+
+	if *sizes != "" {
+		for _, part := range strings.Split(*sizes, ",") {
+			var size, err = strconv.Atoi(part)
+			if err != nil || size < 1 {
+				return fmt.Errorf("bad size %q", part)
+			}
+
+			parsed = append(parsed, size)
+		}
+	}
+
+	var cases = bench.Cases(parsed...)
+
+	if *groups != "" {
+		var wanted = make(map[string]bool)
+		for _, group := range strings.Split(*groups, ",") {
+			wanted[group] = true
+		}
+
+		var filtered = cases[:0]
+		for _, c := range cases {
+			if wanted[c.Group] {
+				filtered = append(filtered, c)
+			}
+		}
+
+		if len(filtered) == 0 {
+			return fmt.Errorf("no cases in %q. want some of %s", *groups, strings.Join(bench.Groups(cases), ", "))
+		}
+
+		cases = filtered
+	}
+
+	var progress io.Writer = os.Stderr
+	if *quiet {
+		progress = nil
+	}
+
+	return bench.Markdown(out, bench.Run(cases, progress))
+}
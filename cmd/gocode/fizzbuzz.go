@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/noriah/go-code/example/fizzbuzz"
+)
+
+// fizzBuzzResult is the JSON output of the fizzbuzz command
+type fizzBuzzResult struct {
+	Impl  string   `json:"impl"`
+	From  int      `json:"from"`
+	To    int      `json:"to"`
+	Lines []string `json:"lines"`
+}
+
+// runFizzBuzz plays FizzBuzz with the chosen strategy
+func runFizzBuzz(args []string, out io.Writer) error {
+	var fs = flag.NewFlagSet("fizzbuzz", flag.ContinueOnError)
+
+	var impl = fs.String("impl", "slice", "strategy: "+strings.Join(fizzbuzz.StrategyNames(), ", "))
+	var from = fs.Int("from", 1, "first number")
+	var to = fs.Int("to", 100, "last number")
+	var rules = fs.String("rules", "3:Fizz,5:Buzz", "comma separated divisor:word rules")
+	var format = formatFlag(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	var strategy, ok = fizzbuzz.Strategies[*impl]
+	if !ok {
+		return fmt.Errorf("unknown impl %q. want one of %s", *impl, strings.Join(fizzbuzz.StrategyNames(), ", "))
+	}
+
+	var parsed, err = parseRules(*rules)
+	if err != nil {
+		return err
+	}
+
+	// One stack frame per number. Counted unsigned so a wide range can't
+	// overflow
+	if *impl == "recursion" && *to >= *from && uint(*to)-uint(*from) >= fizzbuzz.MaxRecursionRange {
+		return fmt.Errorf("impl recursion can play at most %d numbers", fizzbuzz.MaxRecursionRange)
+	}
+
+	var game = fizzbuzz.Game{Rules: parsed, From: *from, To: *to}
+
+	if *format == "text" {
+		return fizzbuzz.Buffered(out, 0, func(w io.Writer) error {
+			return strategy.Play(w, game)
+		})
+	}
+
+	var buf bytes.Buffer
+	if err = strategy.Play(&buf, game); err != nil {
+		return err
+	}
+
+	return writeJSON(out, fizzBuzzResult{
+		Impl:  *impl,
+		From:  *from,
+		To:    *to,
+		Lines: lines(buf.String()),
+	})
+}
+
+// parseRules turns "3:Fizz,5:Buzz" into rules
+func parseRules(text string) ([]fizzbuzz.Rule, error) {
+	var rules []fizzbuzz.Rule
+
+	if text == "" {
+		return rules, nil
+	}
+
+	for _, part := range strings.Split(text, ",") {
+		var idx = strings.IndexByte(part, ':')
+		if idx < 0 {
+			return nil, fmt.Errorf("bad rule %q. want divisor:word", part)
+		}
+
+		var divisor, err = strconv.Atoi(part[:idx])
+		if err != nil {
+			return nil, fmt.Errorf("bad rule %q: %v", part, err)
+		}
+
+		rules = append(rules, fizzbuzz.Rule{Divisor: divisor, Word: part[idx+1:]})
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,105 @@
+// Command gocode runs the examples in this repo from the command line.
+//
+// Usage:
+//
+//	gocode fizzbuzz [--impl=slice] [--from=1] [--to=100] [--rules=3:Fizz,5:Buzz] [--format=text]
+//	gocode primes [--count=100 | --upto=N] [--method=sieve] [--format=text]
+//	gocode table [--format=text]
+//	gocode bench [--group=fizzbuzz,ordered,prime,queue,stack] [--sizes=100,10000] [--quiet]
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// command is a subcommand. It parses its own args and writes to out
+type command func(args []string, out io.Writer) error
+
+var commands = map[string]command{
+	"bench":    runBench,
+	"fizzbuzz": runFizzBuzz,
+	"primes":   runPrimes,
+	"table":    runTable,
+}
+
+// An error to be returned when --format is not text or json
+var errorBadFormat = errors.New("format must be text or json")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		// flag has already printed usage for -h
+		if err != flag.ErrHelp {
+			fmt.Fprintln(os.Stderr, "gocode:", err)
+		}
+
+		os.Exit(2)
+	}
+}
+
+// run picks the subcommand from args and runs it
+func run(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing command. want one of %s", commandNames())
+	}
+
+	var cmd, ok = commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q. want one of %s", args[0], commandNames())
+	}
+
+	return cmd(args[1:], out)
+}
+
+// commandNames returns the subcommand names, sorted and joined
+func commandNames() string {
+	var names = make([]string, 0, len(commands))
+
+	for name := range commands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
+
+// formatFlag adds the shared --format flag to fs
+func formatFlag(fs *flag.FlagSet) *string {
+	return fs.String("format", "text", "output format: text or json")
+}
+
+// checkFormat makes sure format is one we know
+func checkFormat(format string) error {
+	if format != "text" && format != "json" {
+		return errorBadFormat
+	}
+
+	return nil
+}
+
+// writeJSON writes value to out as indented JSON
+func writeJSON(out io.Writer, value interface{}) error {
+	var enc = json.NewEncoder(out)
+
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(value)
+}
+
+// lines splits text output into lines, dropping the trailing newline
+func lines(text string) []string {
+	text = strings.TrimSuffix(text, "\n")
+
+	if text == "" {
+		return []string{}
+	}
+
+	return strings.Split(text, "\n")
+}
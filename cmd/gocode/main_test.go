@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRunFizzBuzz(t *testing.T) {
+	expect := "1 2 Fizz 4 Buzz Fizz 7 8 Fizz Buzz 11 Fizz 13 14 FizzBuzz"
+
+	for _, impl := range []string{"slice", "branchless", "recursion", "channel"} {
+		var out bytes.Buffer
+
+		if err := run([]string{"fizzbuzz", "--impl=" + impl, "--from", "1", "--to", "15"}, &out); err != nil {
+			t.Fatalf("%s: %v", impl, err)
+		}
+
+		if got := strings.Join(lines(out.String()), " "); got != expect {
+			t.Errorf("%s: expected %q, got %q", impl, expect, got)
+		}
+	}
+}
+
+func TestRunFizzBuzzRecursionLimit(t *testing.T) {
+	const limit = "1048576"
+
+	// Exactly the limit still plays
+	if err := run([]string{"fizzbuzz", "--impl=recursion", "--from=1", "--to=" + limit}, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// One more is refused up front, instead of overflowing the stack
+	err := run([]string{"fizzbuzz", "--impl=recursion", "--from=0", "--to=" + limit}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "at most "+limit) {
+		t.Errorf("expected the recursion limit error, got %v", err)
+	}
+}
+
+func TestRunFizzBuzzJSON(t *testing.T) {
+	var out bytes.Buffer
+
+	if err := run([]string{"fizzbuzz", "--rules=2:Foo", "--to=4", "--format=json"}, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	var result fizzBuzzResult
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(result.Lines, " "); got != "1 Foo 3 Foo" {
+		t.Errorf("expected %q, got %q", "1 Foo 3 Foo", got)
+	}
+}
+
+func TestRunPrimes(t *testing.T) {
+	for _, method := range methodNames() {
+		var out bytes.Buffer
+
+		if err := run([]string{"primes", "--count=10", "--method=" + method, "--format=json"}, &out); err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+
+		var result primesResult
+		if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+			t.Fatal(err)
+		}
+
+		if result.Count != 10 || result.Primes[9] != 29 {
+			t.Errorf("%s: expected 10 primes ending in 29, got %v", method, result.Primes)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := run([]string{"primes", "--upto=20"}, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(lines(out.String()), " "); got != "2 3 5 7 11 13 17 19" {
+		t.Errorf("expected primes up to 20, got %q", got)
+	}
+}
+
+func TestRunTable(t *testing.T) {
+	var out bytes.Buffer
+
+	if err := run([]string{"table", "--format=json"}, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	var rows []string
+	if err := json.Unmarshal(out.Bytes(), &rows); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != 100 {
+		t.Errorf("expected %d rows, got %d", 100, len(rows))
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	bad := [][]string{
+		{},
+		{"nope"},
+		{"fizzbuzz", "--impl=nope"},
+		{"fizzbuzz", "--rules=Fizz"},
+		{"fizzbuzz", "--format=xml"},
+		{"fizzbuzz", "--impl=recursion", "--from=1", "--to=20000000"},
+		{"fizzbuzz", "--impl=recursion", "--from=-9223372036854775808", "--to=9223372036854775807"},
+		{"primes", "--method=trial", "--upto=10"},
+		{"primes", "--upto=-5"},
+		{"bench", "--group=nope", "--quiet"},
+		{"bench", "--sizes=ten", "--quiet"},
+	}
+
+	for _, args := range bad {
+		if err := run(args, &bytes.Buffer{}); err == nil {
+			t.Errorf("%v: expected error", args)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/noriah/go-code/example/number/prime"
+)
+
+// primeMethods are the generators the primes command can use.
+// Each takes a count and returns that many primes.
+var primeMethods = map[string]func(count int) []int{
+	"sieve": prime.GenerateCount,
+	"trial": prime.TrialDivision,
+	"segmented": func(count int) []int {
+		return prime.SegmentedSieve(prime.UpperBound(count))[:count]
+	},
+	"wheel": func(count int) []int {
+		return prime.WheelSieve(prime.UpperBound(count))[:count]
+	},
+}
+
+// primeSieves are the generators that can run up to a limit
+var primeSieves = map[string]func(n int) []int{
+	"sieve":     prime.GenerateUpTo,
+	"segmented": prime.SegmentedSieve,
+	"wheel":     prime.WheelSieve,
+}
+
+// primesResult is the JSON output of the primes command
+type primesResult struct {
+	Method string `json:"method"`
+	Count  int    `json:"count"`
+	Primes []int  `json:"primes"`
+}
+
+// runPrimes lists primes by count or by limit
+func runPrimes(args []string, out io.Writer) error {
+	var fs = flag.NewFlagSet("primes", flag.ContinueOnError)
+
+	var count = fs.Int("count", 100, "number of primes to list")
+	var upTo = fs.Int("upto", 0, "list every prime up to this instead of --count")
+	var method = fs.String("method", "sieve", "generator: "+strings.Join(methodNames(), ", "))
+	var format = formatFlag(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	if *upTo < 0 {
+		return errors.New("upto must not be negative")
+	}
+
+	var primes []int
+
+	if *upTo > 0 {
+		var fn, ok = primeSieves[*method]
+		if !ok {
+			return fmt.Errorf("method %q can't be used with --upto", *method)
+		}
+
+		primes = fn(*upTo)
+	} else {
+		var fn, ok = primeMethods[*method]
+		if !ok {
+			return fmt.Errorf("unknown method %q. want one of %s", *method, strings.Join(methodNames(), ", "))
+		}
+
+		if *count < 0 {
+			return errors.New("count must not be negative")
+		}
+
+		primes = fn(*count)
+	}
+
+	if primes == nil {
+		primes = []int{}
+	}
+
+	if *format == "json" {
+		return writeJSON(out, primesResult{
+			Method: *method,
+			Count:  len(primes),
+			Primes: primes,
+		})
+	}
+
+	var buf = bufio.NewWriter(out)
+
+	for _, p := range primes {
+		buf.WriteString(strconv.Itoa(p))
+		buf.WriteByte('\n')
+	}
+
+	return buf.Flush()
+}
+
+// methodNames returns the names in primeMethods, sorted
+func methodNames() []string {
+	var names = make([]string, 0, len(primeMethods))
+
+	for name := range primeMethods {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
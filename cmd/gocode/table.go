@@ -0,0 +1,35 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io"
+
+	"github.com/noriah/go-code/example/fizzbuzz"
+)
+
+// runTable prints the FizzBuzz bit table
+func runTable(args []string, out io.Writer) error {
+	var fs = flag.NewFlagSet("table", flag.ContinueOnError)
+
+	var format = formatFlag(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	if *format == "text" {
+		return fizzbuzz.Buffered(out, 0, fizzbuzz.TableTo)
+	}
+
+	var buf bytes.Buffer
+	if err := fizzbuzz.TableTo(&buf); err != nil {
+		return err
+	}
+
+	return writeJSON(out, lines(buf.String()))
+}
@@ -0,0 +1,126 @@
+// Package bench holds benchmarks for every implementation in this repo.
+//
+// Each Case is a plain testing.B function. The package tests run them as
+// regular benchmarks, and Run can run them from any program with
+// testing.Benchmark, which is how `gocode bench` makes its report.
+package bench
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"testing"
+	"time"
+)
+
+// defaultSizes are the input sizes Cases uses when given none
+var defaultSizes = []int{100, 10000, 100000}
+
+// Case is one implementation at one input size
+type Case struct {
+	Group string // what kind of thing is being measured
+	Name  string // which implementation
+	Size  int    // how many items each op handles
+	Fn    func(b *testing.B)
+}
+
+// Result is a finished Case
+type Result struct {
+	Case
+	testing.BenchmarkResult
+}
+
+// ItemsPerSecond returns how many items the case got through each second
+func (r Result) ItemsPerSecond() float64 {
+	if r.T <= 0 {
+		return 0
+	}
+
+	return float64(r.Size) * float64(r.N) / r.T.Seconds()
+}
+
+// Cases returns every case at each of sizes, sorted by group, name and size.
+// Some cases skip sizes they would take too long at. If no sizes are given,
+// 100, 10000 and 100000 are used.
+func Cases(sizes ...int) []Case {
+	if len(sizes) == 0 {
+		sizes = defaultSizes
+	}
+
+	var cases []Case
+
+	cases = append(cases, fizzBuzzCases(sizes)...)
+	cases = append(cases, primeCases(sizes)...)
+	cases = append(cases, queueCases(sizes)...)
+	cases = append(cases, stackCases(sizes)...)
+	cases = append(cases, orderedCases(sizes)...)
+
+	sort.SliceStable(cases, func(i, j int) bool {
+		if cases[i].Group != cases[j].Group {
+			return cases[i].Group < cases[j].Group
+		}
+
+		if cases[i].Name != cases[j].Name {
+			return cases[i].Name < cases[j].Name
+		}
+
+		return cases[i].Size < cases[j].Size
+	})
+
+	return cases
+}
+
+// Groups returns the group names in cases, in order
+func Groups(cases []Case) []string {
+	var groups []string
+
+	for _, c := range cases {
+		if len(groups) == 0 || groups[len(groups)-1] != c.Group {
+			groups = append(groups, c.Group)
+		}
+	}
+
+	return groups
+}
+
+// Run runs each case with testing.Benchmark.
+// If progress is not nil, a line is written to it as each case finishes.
+func Run(cases []Case, progress io.Writer) []Result {
+	var results = make([]Result, 0, len(cases))
+
+	for _, c := range cases {
+		var start = time.Now()
+		var r = Result{Case: c, BenchmarkResult: testing.Benchmark(c.Fn)}
+
+		if progress != nil {
+			fmt.Fprintf(progress, "%s/%s/%d\t%s\n", c.Group, c.Name, c.Size, time.Since(start).Round(time.Millisecond))
+		}
+
+		results = append(results, r)
+	}
+
+	return results
+}
+
+// Markdown writes results as a markdown table
+func Markdown(w io.Writer, results []Result) error {
+	var _, err = fmt.Fprintln(w, "| Group | Implementation | Size | ns/op | items/s | B/op | allocs/op |")
+	if err != nil {
+		return err
+	}
+
+	if _, err = fmt.Fprintln(w, "|---|---|---:|---:|---:|---:|---:|"); err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		_, err = fmt.Fprintf(w, "| %s | %s | %d | %d | %.0f | %d | %d |\n",
+			r.Group, r.Name, r.Size, r.NsPerOp(), r.ItemsPerSecond(),
+			r.AllocedBytesPerOp(), r.AllocsPerOp())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package bench
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func BenchmarkFizzBuzz(b *testing.B) { runGroup(b, "fizzbuzz") }
+func BenchmarkOrdered(b *testing.B)  { runGroup(b, "ordered") }
+func BenchmarkPrime(b *testing.B)    { runGroup(b, "prime") }
+func BenchmarkQueue(b *testing.B)    { runGroup(b, "queue") }
+func BenchmarkStack(b *testing.B)    { runGroup(b, "stack") }
+
+// runGroup runs every case in group as a sub-benchmark
+func runGroup(b *testing.B, group string) {
+	for _, c := range Cases() {
+		if c.Group != group {
+			continue
+		}
+
+		b.Run(fmt.Sprintf("%s/%d", c.Name, c.Size), c.Fn)
+	}
+}
+
+func TestCases(t *testing.T) {
+	cases := Cases()
+
+	if groups := strings.Join(Groups(cases), ","); groups != "fizzbuzz,ordered,prime,queue,stack" {
+		t.Errorf("unexpected groups %s", groups)
+	}
+
+	seen := make(map[string]bool)
+	for _, c := range cases {
+		key := fmt.Sprintf("%s/%s/%d", c.Group, c.Name, c.Size)
+		if seen[key] {
+			t.Errorf("duplicate case %s", key)
+		}
+		seen[key] = true
+	}
+}
+
+func TestCasesSizes(t *testing.T) {
+	for _, c := range Cases(7, 11) {
+		if c.Size != 7 && c.Size != 11 {
+			t.Errorf("%s/%s: expected size 7 or 11, got %d", c.Group, c.Name, c.Size)
+		}
+	}
+
+	// Asking for sizes leaves the defaults alone
+	for _, c := range Cases() {
+		if c.Size == 7 || c.Size == 11 {
+			t.Errorf("%s/%s: default cases picked up size %d", c.Group, c.Name, c.Size)
+		}
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	var small []Case
+	for _, c := range Cases() {
+		if c.Size == defaultSizes[0] && c.Group == "stack" {
+			small = append(small, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := Markdown(&buf, Run(small, nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(small)+2 {
+		t.Fatalf("expected %d lines, got %d:\n%s", len(small)+2, len(lines), buf.String())
+	}
+
+	if !strings.HasPrefix(lines[2], "| stack | linked | 100 |") {
+		t.Errorf("unexpected row %q", lines[2])
+	}
+}
@@ -0,0 +1,233 @@
+package bench
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"testing"
+
+	"github.com/noriah/go-code/example/fizzbuzz"
+	"github.com/noriah/go-code/example/number/prime"
+	"github.com/noriah/go-code/structure/queue"
+	"github.com/noriah/go-code/structure/queue/channel"
+	"github.com/noriah/go-code/structure/queue/linked"
+	"github.com/noriah/go-code/structure/queue/slice"
+	"github.com/noriah/go-code/structure/skiplist"
+	linkedstack "github.com/noriah/go-code/structure/stack/linked"
+	slicestack "github.com/noriah/go-code/structure/stack/slice"
+	"github.com/noriah/go-code/structure/tree"
+	"github.com/noriah/go-code/structure/tree/avl"
+	"github.com/noriah/go-code/structure/tree/btree"
+	"github.com/noriah/go-code/structure/tree/llrb"
+)
+
+// trialLimit is the largest size trial division is run at. Past this it
+// takes minutes
+const trialLimit = 10000
+
+func fizzBuzzCases(sizes []int) []Case {
+	var cases []Case
+
+	for _, name := range fizzbuzz.StrategyNames() {
+		for _, size := range sizes {
+			var strategy = fizzbuzz.Strategies[name]
+			var game = fizzbuzz.Game{Rules: fizzbuzz.Classic().Rules, From: 1, To: size}
+
+			cases = append(cases, Case{
+				Group: "fizzbuzz",
+				Name:  name,
+				Size:  size,
+				Fn: func(b *testing.B) {
+					b.ReportAllocs()
+
+					for i := 0; i < b.N; i++ {
+						strategy.Play(ioutil.Discard, game)
+					}
+				},
+			})
+		}
+	}
+
+	return cases
+}
+
+func primeCases(sizes []int) []Case {
+	var generators = map[string]func(count int) []int{
+		"GenerateCount": prime.GenerateCount,
+		"TrialDivision": prime.TrialDivision,
+		"Sieve": func(count int) []int {
+			return prime.Sieve(prime.UpperBound(count))
+		},
+		"SegmentedSieve": func(count int) []int {
+			return prime.SegmentedSieve(prime.UpperBound(count))
+		},
+		"WheelSieve": func(count int) []int {
+			return prime.WheelSieve(prime.UpperBound(count))
+		},
+		"Iterator": func(count int) []int {
+			var it = prime.NewIterator()
+			for i := 0; i < count; i++ {
+				it.Next()
+			}
+			return nil
+		},
+	}
+
+	var cases []Case
+
+	for name, generate := range generators {
+		for _, size := range sizes {
+			if name == "TrialDivision" && size > trialLimit {
+				continue
+			}
+
+			var generate, size = generate, size
+
+			cases = append(cases, Case{
+				Group: "prime",
+				Name:  name,
+				Size:  size,
+				Fn: func(b *testing.B) {
+					b.ReportAllocs()
+
+					for i := 0; i < b.N; i++ {
+						generate(size)
+					}
+				},
+			})
+		}
+	}
+
+	return cases
+}
+
+func queueCases(sizes []int) []Case {
+	var queues = map[string]func(size int) queue.Queue{
+		"linked": func(int) queue.Queue { return linked.New() },
+		"slice":  func(int) queue.Queue { return queue.FromUnbounded(slice.New()) },
+		"channel": func(size int) queue.Queue {
+			return channel.New(size)
+		},
+	}
+
+	var cases []Case
+
+	for name, newQueue := range queues {
+		for _, size := range sizes {
+			var newQueue, size = newQueue, size
+
+			cases = append(cases, Case{
+				Group: "queue",
+				Name:  name,
+				Size:  size,
+				Fn: func(b *testing.B) {
+					b.ReportAllocs()
+
+					for i := 0; i < b.N; i++ {
+						var q = newQueue(size)
+
+						for n := 0; n < size; n++ {
+							q.Push(n)
+						}
+
+						for n := 0; n < size; n++ {
+							q.Pop()
+						}
+					}
+				},
+			})
+		}
+	}
+
+	return cases
+}
+
+// stack is the surface both stacks share
+type stack interface {
+	Push(value interface{})
+	Pop() (interface{}, error)
+}
+
+func stackCases(sizes []int) []Case {
+	var stacks = map[string]func() stack{
+		"linked": func() stack { return &linkedstack.Stack{} },
+		"slice":  func() stack { return &slicestack.Stack{} },
+	}
+
+	var cases []Case
+
+	for name, newStack := range stacks {
+		for _, size := range sizes {
+			var newStack, size = newStack, size
+
+			cases = append(cases, Case{
+				Group: "stack",
+				Name:  name,
+				Size:  size,
+				Fn: func(b *testing.B) {
+					b.ReportAllocs()
+
+					for i := 0; i < b.N; i++ {
+						var s = newStack()
+
+						for n := 0; n < size; n++ {
+							s.Push(n)
+						}
+
+						for n := 0; n < size; n++ {
+							s.Pop()
+						}
+					}
+				},
+			})
+		}
+	}
+
+	return cases
+}
+
+// orderedMap is the surface the ordered maps share
+type orderedMap interface {
+	Put(key, value interface{})
+	Get(key interface{}) (interface{}, error)
+}
+
+func orderedCases(sizes []int) []Case {
+	var maps = map[string]func() orderedMap{
+		"skiplist":   func() orderedMap { return skiplist.New(tree.Ints) },
+		"concurrent": func() orderedMap { return skiplist.NewConcurrent(tree.Ints) },
+		"avl":        func() orderedMap { return avl.New(tree.Ints) },
+		"llrb":       func() orderedMap { return llrb.New(tree.Ints) },
+		"btree":      func() orderedMap { return btree.New(tree.Ints) },
+	}
+
+	var cases []Case
+
+	for name, newMap := range maps {
+		for _, size := range sizes {
+			var newMap, keys = newMap, rand.New(rand.NewSource(1)).Perm(size)
+
+			cases = append(cases, Case{
+				Group: "ordered",
+				Name:  name,
+				Size:  size,
+				Fn: func(b *testing.B) {
+					b.ReportAllocs()
+
+					for i := 0; i < b.N; i++ {
+						var m = newMap()
+
+						for _, key := range keys {
+							m.Put(key, nil)
+						}
+
+						for _, key := range keys {
+							m.Get(key)
+						}
+					}
+				},
+			})
+		}
+	}
+
+	return cases
+}
@@ -1,9 +1,20 @@
 package fizzbuzz
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+	"os"
+)
 
-// Branchless does FizzBuzz by shifting, without branches
+// Branchless does FizzBuzz by shifting, without branches, printing to stdout
 func Branchless() {
+	BranchlessTo(os.Stdout)
+}
+
+// BranchlessTo does FizzBuzz by shifting, without branches, writing to w.
+// It stops at the first write error and returns it. That check is the one
+// branch we allow ourselves.
+func BranchlessTo(w io.Writer) error {
 	// declare our vars
 	var num, start, end int
 
@@ -36,6 +47,54 @@ func Branchless() {
 
 		// Print it out
 		// if we divide start by end (integer math only), we can select the right key
-		fmt.Printf(key[start/end], num, word[start:end])
+		if _, err := fmt.Fprintf(w, key[start/end], num, word[start:end]); err != nil {
+			return err
+		}
 	}
+
+	return nil
+}
+
+// BranchlessStrategy plays a Game without branching on the rules.
+//
+// Each rule's word is sliced to either its full length or nothing by
+// multiplying the length by the divisibility flag. The OR of the flags
+// then picks the format key, just like Branchless.
+type BranchlessStrategy struct{}
+
+// Play plays g, writing to w
+func (BranchlessStrategy) Play(w io.Writer, g Game) error {
+	if err := g.check(); err != nil {
+		return err
+	}
+
+	// Keys for printf
+	var key = [2]string{"%[1]d\n", "%[2]s\n"}
+
+	var line []byte
+	var num, idx, hit, flag int
+
+	for num = g.From; num <= g.To; num++ {
+		line = line[:0]
+		flag = 0
+
+		for idx = range g.Rules {
+			hit = divides(num, g.Rules[idx].Divisor)
+			flag |= hit
+
+			// Either the whole word or none of it
+			line = append(line, g.Rules[idx].Word[:len(g.Rules[idx].Word)*hit]...)
+		}
+
+		if _, err := fmt.Fprintf(w, key[flag], num, line); err != nil {
+			return err
+		}
+
+		// Stop on To itself. num++ would wrap past the largest int
+		if num == g.To {
+			break
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,29 @@
+package fizzbuzz
+
+import (
+	"bufio"
+	"io"
+)
+
+// Buffered runs fn with a buffered writer of size bytes in front of w, then
+// flushes it. A size less than 1 uses the bufio default.
+//
+// Every FizzBuzz line is a tiny write. Buffering them makes a big difference
+// when w is a file or a terminal.
+//
+//	fizzbuzz.Buffered(os.Stdout, 4096, fizzbuzz.SliceTo)
+func Buffered(w io.Writer, size int, fn func(io.Writer) error) error {
+	var buf *bufio.Writer
+
+	if size < 1 {
+		buf = bufio.NewWriter(w)
+	} else {
+		buf = bufio.NewWriterSize(w, size)
+	}
+
+	if err := fn(buf); err != nil {
+		return err
+	}
+
+	return buf.Flush()
+}
@@ -1,16 +1,28 @@
 package fizzbuzz
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+	"os"
+)
 
-// Channel does fizzbuzz with channels
+// Channel does fizzbuzz with channels, printing to stdout
 func Channel() {
+	ChannelTo(os.Stdout)
+}
+
+// ChannelTo does fizzbuzz with channels, writing to w.
+// The printer keeps the first write error and skips writing after it.
+func ChannelTo(w io.Writer) error {
 
 	var num, div3, div5 int
 
 	var numChannel = make(chan int)
 	var doneChannel = make(chan bool)
 
-	go channelPrinter(numChannel, doneChannel)
+	var err error
+
+	go channelPrinter(w, &err, numChannel, doneChannel)
 
 	for num = 1; num <= 100; num++ {
 
@@ -21,10 +33,13 @@ func Channel() {
 	}
 
 	doneChannel <- true
+
+	// The printer took our done, so it is no longer touching err
+	return err
 }
 
 // This function is very unsafe
-func channelPrinter(numCh <-chan int, doneCh <-chan bool) {
+func channelPrinter(w io.Writer, err *error, numCh <-chan int, doneCh <-chan bool) {
 
 	const word = "FizzBuzz"
 
@@ -33,16 +48,107 @@ func channelPrinter(numCh <-chan int, doneCh <-chan bool) {
 	for {
 		select {
 		case value = <-numCh:
+			// Already failed. Keep taking values so the sender never blocks
+			if *err != nil {
+				continue
+			}
+
 			if value > 100 {
 				value >>= 7
-				fmt.Println(word[4-((value&0x01)<<2) : 4+((value&0x02)<<1)])
+				_, *err = fmt.Fprintln(w, word[4-((value&0x01)<<2):4+((value&0x02)<<1)])
 				continue
 			}
 
-			fmt.Println(value)
+			_, *err = fmt.Fprintln(w, value)
 
 		case <-doneCh:
 			return
 		}
 	}
 }
+
+// channelValue is a number and a mask of the rules it matched
+type channelValue struct {
+	num  int
+	mask uint64
+}
+
+// ChannelStrategy plays a Game with a producer and a printer connected by
+// a channel.
+//
+// The producer works out which rules match and sends a mask along with the
+// number. The printer turns the mask back into words. Closing the channel
+// tells the printer we are done, and the printer reports back on its own
+// channel, so nothing is left running when Play returns.
+type ChannelStrategy struct{}
+
+// Play plays g, writing to w
+func (ChannelStrategy) Play(w io.Writer, g Game) error {
+	if err := g.check(); err != nil {
+		return err
+	}
+
+	var numChannel = make(chan channelValue)
+	var quitChannel = make(chan struct{})
+	var errChannel = make(chan error, 1)
+
+	go func() {
+		errChannel <- channelStrategyPrinter(w, g.Rules, numChannel, quitChannel)
+	}()
+
+	var mask uint64
+
+	// Send numbers until we are done or the printer gives up
+loop:
+	for num := g.From; num <= g.To; num++ {
+		mask = 0
+
+		for idx, r := range g.Rules {
+			mask |= uint64(divides(num, r.Divisor)) << uint(idx)
+		}
+
+		select {
+		case numChannel <- channelValue{num: num, mask: mask}:
+		case <-quitChannel:
+			break loop
+		}
+
+		// Stop on To itself. num++ would wrap past the largest int
+		if num == g.To {
+			break
+		}
+	}
+
+	close(numChannel)
+
+	return <-errChannel
+}
+
+// channelStrategyPrinter writes every value it receives until numCh is
+// closed. On a write error it closes quitCh and returns the error.
+func channelStrategyPrinter(w io.Writer, rules []Rule, numCh <-chan channelValue, quitCh chan<- struct{}) error {
+	var line []byte
+
+	for value := range numCh {
+		line = line[:0]
+
+		for idx, r := range rules {
+			if value.mask&(1<<uint(idx)) != 0 {
+				line = append(line, r.Word...)
+			}
+		}
+
+		if value.mask != 0 {
+			line = append(line, '\n')
+		} else {
+			line = appendNumber(line, value.num)
+		}
+
+		if _, err := w.Write(line); err != nil {
+			close(quitCh)
+			return err
+		}
+	}
+
+	return nil
+}
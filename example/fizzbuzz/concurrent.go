@@ -0,0 +1,185 @@
+package fizzbuzz
+
+import (
+	"bytes"
+	"io"
+	"runtime"
+	"sync"
+)
+
+const defaultChunkSize = 1024
+
+// ConcurrentStrategy plays a Game on several goroutines and still writes
+// the output in order.
+//
+// The range is cut into chunks of Chunk numbers. Workers take chunks from
+// a channel and render each one into a buffer with BranchlessStrategy. The
+// writer keeps finished chunks in a reorder buffer until every chunk before
+// them has been written.
+//
+// The producer only runs a few chunks ahead of the writer. It takes a slot
+// from a window before handing out a chunk, and the writer gives the slot
+// back once that chunk is written. A slow chunk can hold up at most the
+// window, so the reorder buffer never grows past it.
+//
+// Shutdown follows the channels. The producer closes the chunk channel when
+// it runs out, the workers finish and a closer goroutine closes the result
+// channel once they are all gone. The writer ranges over results, so when
+// Play returns, every goroutine has exited. Compare with Channel, which
+// needs a separate done channel to tell its printer to stop.
+type ConcurrentStrategy struct {
+	// Workers is the number of goroutines rendering chunks.
+	// 0 means one per CPU.
+	Workers int
+
+	// Chunk is how many numbers each worker renders at a time.
+	// 0 means 1024.
+	Chunk int
+}
+
+// concurrentChunk is one piece of the range
+type concurrentChunk struct {
+	idx  int
+	from int
+	to   int
+}
+
+// concurrentResult is a rendered chunk
+type concurrentResult struct {
+	idx int
+	buf []byte
+	err error
+}
+
+// Play plays g, writing to w
+func (s ConcurrentStrategy) Play(w io.Writer, g Game) error {
+	if err := g.check(); err != nil {
+		return err
+	}
+
+	var workers, chunk = s.Workers, s.Chunk
+
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+
+	if chunk < 1 {
+		chunk = defaultChunkSize
+	}
+
+	var chunks = make(chan concurrentChunk)
+	var results = make(chan concurrentResult, workers)
+
+	// window holds a slot for every chunk handed out but not yet written
+	var window = make(chan struct{}, 2*workers)
+
+	// quit is closed when the writer gives up, so nobody blocks forever
+	var quit = make(chan struct{})
+
+	// Producer. Cut the range into chunks
+	go concurrentProduce(g, chunk, chunks, window, quit)
+
+	// Workers. Render chunks until there are none left
+	var wg sync.WaitGroup
+
+	wg.Add(workers)
+
+	for n := 0; n < workers; n++ {
+		go func() {
+			defer wg.Done()
+
+			for c := range chunks {
+				var buf bytes.Buffer
+				var err = BranchlessStrategy{}.Play(&buf, Game{Rules: g.Rules, From: c.from, To: c.to})
+
+				select {
+				case results <- concurrentResult{idx: c.idx, buf: buf.Bytes(), err: err}:
+				case <-quit:
+					return
+				}
+			}
+		}()
+	}
+
+	// Closer. No workers, no more results
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Writer. Hold chunks that arrive early until their turn. The window
+	// keeps pending to at most 2*workers chunks
+	var pending = make(map[int][]byte)
+	var next int
+	var err error
+
+	for r := range results {
+		// Already failed. Just drain so the closer can finish
+		if err != nil {
+			continue
+		}
+
+		if r.err != nil {
+			err = r.err
+			close(quit)
+			continue
+		}
+
+		pending[r.idx] = r.buf
+
+		for buf, ok := pending[next]; ok; buf, ok = pending[next] {
+			delete(pending, next)
+			next++
+
+			// Let the producer hand out another chunk
+			<-window
+
+			if _, err = w.Write(buf); err != nil {
+				close(quit)
+				break
+			}
+		}
+	}
+
+	return err
+}
+
+// Helper Methods
+// These methods are used internally.
+
+// concurrentProduce cuts g into chunks of size numbers and sends them on
+// chunks, which it closes when done. It takes a slot from window before
+// each chunk, so it never gets more than cap(window) chunks ahead of
+// whoever empties window.
+func concurrentProduce(g Game, size int, chunks chan<- concurrentChunk, window chan<- struct{}, quit <-chan struct{}) {
+	defer close(chunks)
+
+	for idx, from := 0, g.From; from <= g.To; idx++ {
+		// Count what is left unsigned, so nothing overflows near the
+		// ends of int
+		var to = g.To
+		if uint(g.To-from) >= uint(size) {
+			to = from + size - 1
+		}
+
+		// Wait for the writer to catch up
+		select {
+		case window <- struct{}{}:
+		case <-quit:
+			return
+		}
+
+		select {
+		case chunks <- concurrentChunk{idx: idx, from: from, to: to}:
+		case <-quit:
+			return
+		}
+
+		// Stop on To itself. Stepping past it could wrap around
+		if to == g.To {
+			return
+		}
+
+		from = to + 1
+	}
+}
@@ -0,0 +1,107 @@
+package fizzbuzz
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestConcurrentMatchesSequential(t *testing.T) {
+	games := []Game{
+		Classic(),
+		{Rules: []Rule{{3, "Fizz"}, {5, "Buzz"}, {7, "Bazz"}}, From: -1000, To: 100000},
+		{Rules: []Rule{{3, "Fizz"}}, From: 7, To: 7},
+		{Rules: []Rule{{3, "Fizz"}}, From: 7, To: 6},
+	}
+
+	strategies := []ConcurrentStrategy{
+		{},
+		{Workers: 1, Chunk: 1},
+		{Workers: 3, Chunk: 7},
+		{Workers: 16, Chunk: 100},
+		{Workers: 64, Chunk: 5000},
+	}
+
+	for _, game := range games {
+		var expect bytes.Buffer
+		if err := (SliceStrategy{}).Play(&expect, game); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, s := range strategies {
+			var got bytes.Buffer
+			if err := s.Play(&got, game); err != nil {
+				t.Fatalf("%+v: %v", s, err)
+			}
+
+			if !bytes.Equal(got.Bytes(), expect.Bytes()) {
+				t.Errorf("%+v on %d..%d: output differs from sequential", s, game.From, game.To)
+			}
+		}
+	}
+}
+
+func TestConcurrentNearMaxInt(t *testing.T) {
+	const maxInt = int(^uint(0) >> 1)
+
+	games := []Game{
+		{Rules: []Rule{{3, "Fizz"}}, From: maxInt - 20, To: maxInt - 1},
+		{Rules: []Rule{{3, "Fizz"}}, From: maxInt - 20, To: maxInt - 3},
+	}
+
+	for _, game := range games {
+		for _, s := range []ConcurrentStrategy{{Workers: 2, Chunk: 4}, {Workers: 2, Chunk: 1000}} {
+			var buf bytes.Buffer
+			if err := s.Play(&buf, game); err != nil {
+				t.Fatalf("%+v: %v", s, err)
+			}
+
+			if lines := bytes.Count(buf.Bytes(), []byte("\n")); lines != game.To-game.From+1 {
+				t.Errorf("%+v on %d..%d: expected %d lines, got %d", s, game.From, game.To, game.To-game.From+1, lines)
+			}
+		}
+	}
+}
+
+func TestConcurrentWriteError(t *testing.T) {
+	game := Game{Rules: Classic().Rules, From: 1, To: 100000}
+
+	err := ConcurrentStrategy{Workers: 8, Chunk: 10}.Play(&failWriter{n: 3}, game)
+	if err != errorWrite {
+		t.Errorf("expected %v, got %v", errorWrite, err)
+	}
+}
+
+func TestConcurrentProduceWindow(t *testing.T) {
+	var chunks = make(chan concurrentChunk)
+	var window = make(chan struct{}, 3)
+	var quit = make(chan struct{})
+
+	go concurrentProduce(Game{Rules: Classic().Rules, From: 1, To: 1000}, 10, chunks, window, quit)
+
+	// Nobody writes, so only the first three chunks come out
+	for idx := 0; idx < 3; idx++ {
+		if c := <-chunks; c.idx != idx {
+			t.Fatalf("expected chunk %d, got %d", idx, c.idx)
+		}
+	}
+
+	select {
+	case c := <-chunks:
+		t.Fatalf("expected the producer to wait, got chunk %d", c.idx)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Writing one chunk lets exactly one more out
+	<-window
+
+	if c := <-chunks; c.idx != 3 {
+		t.Fatalf("expected chunk 3, got %d", c.idx)
+	}
+
+	close(quit)
+
+	// The producer closes chunks on its way out
+	for range chunks {
+	}
+}
@@ -0,0 +1,99 @@
+package fizzbuzz
+
+import (
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// An error to be returned when a rule has a divisor less than 1
+var errorBadDivisor = errors.New("divisor must be at least 1")
+
+// An error to be returned when a game has more rules than fit in a mask
+var errorTooManyRules = errors.New("too many rules")
+
+// maxRules is the most rules a game may have. Strategies keep one bit per
+// rule in a uint64.
+const maxRules = 64
+
+// Rule replaces a number divisible by Divisor with Word.
+// When several rules match, their words are joined in rule order.
+type Rule struct {
+	Divisor int
+	Word    string
+}
+
+// Game is a set of rules played over the numbers From to To, inclusive.
+// A number no rule matches is printed as is.
+type Game struct {
+	Rules []Rule
+	From  int
+	To    int
+}
+
+// Classic returns the game everyone knows. Fizz on 3, Buzz on 5, 1 to 100.
+func Classic() Game {
+	return Game{
+		Rules: []Rule{{3, "Fizz"}, {5, "Buzz"}},
+		From:  1,
+		To:    100,
+	}
+}
+
+// Strategy is a way of playing a Game.
+// Play writes one line per number to w, stopping at the first write error.
+type Strategy interface {
+	Play(w io.Writer, g Game) error
+}
+
+// Strategies holds every strategy by name
+var Strategies = map[string]Strategy{
+	"slice":      SliceStrategy{},
+	"branchless": BranchlessStrategy{},
+	"recursion":  RecursionStrategy{},
+	"channel":    ChannelStrategy{},
+	"concurrent": ConcurrentStrategy{},
+}
+
+// StrategyNames returns the names in Strategies, sorted
+func StrategyNames() []string {
+	var names = make([]string, 0, len(Strategies))
+
+	for name := range Strategies {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// check returns an error if the game can't be played
+func (g Game) check() error {
+	if len(g.Rules) > maxRules {
+		return errorTooManyRules
+	}
+
+	for _, r := range g.Rules {
+		if r.Divisor < 1 {
+			return errorBadDivisor
+		}
+	}
+
+	return nil
+}
+
+// divides returns 1 if d divides num and 0 otherwise, without branching.
+//
+// Shifting 1 right by anything but 0 leaves 0. A negative remainder turns
+// into a huge shift, which leaves 0 too, so nothing is added that could
+// overflow.
+func divides(num, d int) int {
+	return 1 >> uint(num%d)
+}
+
+// appendNumber appends num and a newline to line
+func appendNumber(line []byte, num int) []byte {
+	return append(strconv.AppendInt(line, int64(num), 10), '\n')
+}
@@ -0,0 +1,163 @@
+package fizzbuzz
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"strconv"
+	"testing"
+)
+
+// reference plays g the obvious way
+func reference(g Game) string {
+	var buf bytes.Buffer
+
+	for num := g.From; num <= g.To; num++ {
+		var line string
+		var hit bool
+
+		for _, r := range g.Rules {
+			if num%r.Divisor == 0 {
+				line += r.Word
+				hit = true
+			}
+		}
+
+		if !hit {
+			line = strconv.Itoa(num)
+		}
+
+		buf.WriteString(line + "\n")
+
+		if num == g.To {
+			break
+		}
+	}
+
+	return buf.String()
+}
+
+func TestStrategiesGolden(t *testing.T) {
+	golden, err := ioutil.ReadFile("testdata/classic.golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range StrategyNames() {
+		var buf bytes.Buffer
+
+		if err := Strategies[name].Play(&buf, Classic()); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if !bytes.Equal(buf.Bytes(), golden) {
+			t.Errorf("%s: output does not match testdata/classic.golden", name)
+		}
+	}
+}
+
+func TestStrategiesAgree(t *testing.T) {
+	games := []Game{
+		{Rules: []Rule{{2, "Foo"}, {7, "Bar"}, {11, "Baz"}}, From: -50, To: 500},
+		{Rules: []Rule{{1, "Every"}}, From: 0, To: 10},
+		{Rules: []Rule{{4, ""}, {6, "Six"}}, From: 1, To: 30},
+		{Rules: nil, From: 5, To: 9},
+		{Rules: []Rule{{3, "Fizz"}}, From: 10, To: 1},
+	}
+
+	for _, game := range games {
+		expect := reference(game)
+
+		for _, name := range StrategyNames() {
+			var buf bytes.Buffer
+
+			if err := Strategies[name].Play(&buf, game); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+
+			if got := buf.String(); got != expect {
+				t.Errorf("%s on %+v: expected\n%s\ngot\n%s", name, game, expect, got)
+			}
+		}
+	}
+}
+
+// failWriter accepts n writes, then fails
+type failWriter struct {
+	n int
+}
+
+var errorWrite = errors.New("write failed")
+
+func (f *failWriter) Write(p []byte) (int, error) {
+	if f.n == 0 {
+		return 0, errorWrite
+	}
+	f.n--
+	return len(p), nil
+}
+
+func TestStrategiesIntLimits(t *testing.T) {
+	const maxInt = int(^uint(0) >> 1)
+	const minInt = -maxInt - 1
+
+	games := []Game{
+		{Rules: []Rule{{3, "Fizz"}, {maxInt, "Max"}}, From: maxInt - 10, To: maxInt},
+		{Rules: []Rule{{2, "Even"}}, From: maxInt, To: maxInt},
+		{Rules: []Rule{{maxInt, "Big"}, {maxInt - 1, "Bigger"}}, From: minInt, To: minInt + 10},
+		{Rules: []Rule{{maxInt, "Big"}}, From: -maxInt, To: -maxInt + 2},
+	}
+
+	for _, game := range games {
+		expect := reference(game)
+
+		for _, name := range StrategyNames() {
+			var buf bytes.Buffer
+
+			if err := Strategies[name].Play(&buf, game); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+
+			if got := buf.String(); got != expect {
+				t.Errorf("%s on %+v: expected\n%s\ngot\n%s", name, game, expect, got)
+			}
+		}
+	}
+}
+
+func TestStrategiesFullRange(t *testing.T) {
+	const maxInt = int(^uint(0) >> 1)
+	const minInt = -maxInt - 1
+
+	// To - From does not fit in an int. Every strategy must keep going
+	// until the writer gives up, not stop early or run forever
+	full := Game{Rules: Classic().Rules, From: minInt, To: maxInt}
+
+	for _, name := range StrategyNames() {
+		w := &failWriter{n: 10}
+
+		if err := Strategies[name].Play(w, full); err != errorWrite {
+			t.Errorf("%s: expected %v, got %v", name, errorWrite, err)
+		}
+
+		if w.n != 0 {
+			t.Errorf("%s: expected 10 writes before the write failed, got %d", name, 10-w.n)
+		}
+	}
+}
+
+func TestStrategiesErrors(t *testing.T) {
+	// Long enough that even the chunked strategies write more than 10 times
+	long := Game{Rules: Classic().Rules, From: 1, To: 20000}
+
+	for _, name := range StrategyNames() {
+		if err := Strategies[name].Play(&failWriter{n: 10}, long); err != errorWrite {
+			t.Errorf("%s: expected %v, got %v", name, errorWrite, err)
+		}
+
+		bad := Game{Rules: []Rule{{0, "Zero"}}, From: 1, To: 10}
+		if err := Strategies[name].Play(ioutil.Discard, bad); err != errorBadDivisor {
+			t.Errorf("%s: expected %v, got %v", name, errorBadDivisor, err)
+		}
+	}
+}
@@ -1,9 +1,19 @@
 package fizzbuzz
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+	"os"
+)
 
-// Recursion does FizzBuzz with recursion
+// Recursion does FizzBuzz with recursion, printing to stdout
 func Recursion() {
+	RecursionTo(os.Stdout)
+}
+
+// RecursionTo does FizzBuzz with recursion, writing to w.
+// It stops at the first write error and returns it.
+func RecursionTo(w io.Writer) error {
 
 	// Our string of FizzBuzz
 	const word = "FizzBuzz"
@@ -12,7 +22,8 @@ func Recursion() {
 	var key = []string{"%[2]s\n", "%[1]d\n"}
 
 	// declare our vars
-	var start, end int
+	var start, end, failed int
+	var err error
 
 	// define the variable so it in scope before the functions are defined
 	var fns []func(int)
@@ -42,14 +53,16 @@ func Recursion() {
 
 			// Print it out
 			// if we divide start by end (integer math only), we can select the right key
-			fmt.Printf(key[start/end], num, word[start:end])
+			if _, err = fmt.Fprintf(w, key[start/end], num, word[start:end]); err != nil {
+				failed = 1
+			}
 
 			// Call a function based on what num is.
 			// Divide num by 100. Integer math will truncate anything less than 1 to 0.
 			// Use that value to select next function. If its 0, then we continue the "loop"
 			// and call this function again. If its 1, then we call the noop function, ending
-			// the "loop".
-			fns[num/100](num + 1)
+			// the "loop". A failed write ORs in a 1, ending it early.
+			fns[num/100|failed](num + 1)
 		},
 
 		// A noop function to end the recursion
@@ -58,4 +71,74 @@ func Recursion() {
 
 	// Call our function with our first number
 	fns[0](1)
+
+	return err
+}
+
+// RecursionStrategy plays a Game with recursion instead of a loop.
+//
+// Like Recursion, the next call is picked from a table of functions. The
+// index is 1 once num reaches To, and 0 before. A write error also selects
+// the no-op, ending the recursion early.
+//
+// Every number is a stack frame. Keep the range within MaxRecursionRange.
+type RecursionStrategy struct{}
+
+// MaxRecursionRange is the most numbers RecursionStrategy should be asked
+// to play. A few times more overflows the goroutine stack, which crashes
+// the whole program rather than returning an error.
+const MaxRecursionRange = 1 << 20
+
+// Play plays g, writing to w
+func (RecursionStrategy) Play(w io.Writer, g Game) error {
+	if err := g.check(); err != nil {
+		return err
+	}
+
+	// An empty range. Nothing to do
+	if g.To < g.From {
+		return nil
+	}
+
+	// Keys for printf
+	var key = [2]string{"%[1]d\n", "%[2]s\n"}
+
+	var line []byte
+	var err error
+	var failed int
+
+	// define the variable so it in scope before the functions are defined
+	var fns [2]func(int)
+
+	fns = [2]func(int){
+		// Our main recursion function
+		func(num int) {
+			var flag, hit int
+
+			line = line[:0]
+
+			for _, r := range g.Rules {
+				hit = divides(num, r.Divisor)
+				flag |= hit
+				line = append(line, r.Word[:len(r.Word)*hit]...)
+			}
+
+			if _, err = fmt.Fprintf(w, key[flag], num, line); err != nil {
+				failed = 1
+			}
+
+			// Stop on To. The distance to it, counted unsigned so it
+			// cannot overflow, is 0 only there, and shifting 1 right by
+			// anything else leaves 0
+			fns[1>>(uint(g.To)-uint(num))|failed](num + 1)
+		},
+
+		// A noop function to end the recursion
+		func(_ int) {},
+	}
+
+	// Call our function with our first number
+	fns[0](g.From)
+
+	return err
 }
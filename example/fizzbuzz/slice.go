@@ -1,9 +1,19 @@
 package fizzbuzz
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+	"os"
+)
 
-// Slice does FizzBuzz by slicing a string
+// Slice does FizzBuzz by slicing a string, printing to stdout
 func Slice() {
+	SliceTo(os.Stdout)
+}
+
+// SliceTo does FizzBuzz by slicing a string, writing to w.
+// It stops at the first write error and returns it.
+func SliceTo(w io.Writer) error {
 
 	// declare our vars
 	var num, d3, d5, start, end int
@@ -36,13 +46,77 @@ func Slice() {
 			end = 4 + (d5 * 4)
 
 			// Print it out
-			fmt.Println(word[start:end])
+			if _, err := fmt.Fprintln(w, word[start:end]); err != nil {
+				return err
+			}
 
 			// go to the beginning of this loop
 			continue
 		}
 
 		// If we don't have a flag, just print the number
-		fmt.Println(num)
+		if _, err := fmt.Fprintln(w, num); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SliceStrategy plays a Game by slicing one long string of every rule word.
+//
+// The words are joined up front, the same way "FizzBuzz" holds both "Fizz"
+// and "Buzz". Each matching rule slices its own word back out.
+type SliceStrategy struct{}
+
+// Play plays g, writing to w
+func (SliceStrategy) Play(w io.Writer, g Game) error {
+	if err := g.check(); err != nil {
+		return err
+	}
+
+	// Join the words, remembering where each one starts
+	var word string
+	var offsets = make([]int, len(g.Rules)+1)
+
+	for i, r := range g.Rules {
+		word += r.Word
+		offsets[i+1] = len(word)
+	}
+
+	var line []byte
+	var num, idx, hit, flag int
+
+	for num = g.From; num <= g.To; num++ {
+		line = line[:0]
+		flag = 0
+
+		for idx = range g.Rules {
+			hit = divides(num, g.Rules[idx].Divisor)
+			flag |= hit
+
+			// Slice out this rule's word if it matched
+			if hit > 0 {
+				line = append(line, word[offsets[idx]:offsets[idx+1]]...)
+			}
+		}
+
+		// Did we get a flag set?
+		if flag > 0 {
+			line = append(line, '\n')
+		} else {
+			line = appendNumber(line, num)
+		}
+
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+
+		// Stop on To itself. num++ would wrap past the largest int
+		if num == g.To {
+			break
+		}
 	}
+
+	return nil
 }
@@ -2,10 +2,18 @@ package fizzbuzz
 
 import (
 	"fmt"
+	"io"
+	"os"
 )
 
-// Table prints a table with binary representation
+// Table prints a table with binary representation to stdout
 func Table() {
+	TableTo(os.Stdout)
+}
+
+// TableTo writes a table with binary representation to w.
+// It stops at the first write error and returns it.
+func TableTo(w io.Writer) error {
 
 	// define our variables
 	var num, div3, div5, div15, flag int
@@ -45,6 +53,10 @@ func Table() {
 		star = rune(0x20 + flag*0x0a)
 
 		// Print the row
-		fmt.Printf(fmtStr, star, num, div3, div5, div15)
+		if _, err := fmt.Fprintf(w, fmtStr, star, num, div3, div5, div15); err != nil {
+			return err
+		}
 	}
+
+	return nil
 }
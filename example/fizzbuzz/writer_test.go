@@ -0,0 +1,99 @@
+package fizzbuzz
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+var writerTests = []struct {
+	name   string
+	fn     func(w io.Writer) error
+	golden string
+}{
+	{"SliceTo", SliceTo, "testdata/classic.golden"},
+	{"BranchlessTo", BranchlessTo, "testdata/classic.golden"},
+	{"RecursionTo", RecursionTo, "testdata/classic.golden"},
+	{"ChannelTo", ChannelTo, "testdata/classic.golden"},
+	{"TableTo", TableTo, "testdata/table.golden"},
+}
+
+func TestWriters(t *testing.T) {
+	for _, test := range writerTests {
+		t.Run(test.name, func(t *testing.T) {
+			golden, err := ioutil.ReadFile(test.golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+			if err := test.fn(&buf); err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(buf.Bytes(), golden) {
+				t.Errorf("output does not match %s", test.golden)
+			}
+		})
+	}
+}
+
+func TestWritersBuffered(t *testing.T) {
+	for _, test := range writerTests {
+		t.Run(test.name, func(t *testing.T) {
+			golden, err := ioutil.ReadFile(test.golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, size := range []int{0, 16, 4096} {
+				var buf bytes.Buffer
+				if err := Buffered(&buf, size, test.fn); err != nil {
+					t.Fatal(err)
+				}
+
+				if !bytes.Equal(buf.Bytes(), golden) {
+					t.Errorf("size %d: output does not match %s", size, test.golden)
+				}
+			}
+		})
+	}
+}
+
+func TestWritersErrors(t *testing.T) {
+	for _, test := range writerTests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.fn(&failWriter{n: 10}); err != errorWrite {
+				t.Errorf("expected %v, got %v", errorWrite, err)
+			}
+
+			// The buffer hides the failure until it fills or flushes
+			if err := Buffered(&failWriter{n: 0}, 0, test.fn); err != errorWrite {
+				t.Errorf("buffered: expected %v, got %v", errorWrite, err)
+			}
+		})
+	}
+}
+
+func ExampleSliceTo() {
+	var buf bytes.Buffer
+
+	SliceTo(&buf)
+
+	// Just the first 15
+	lines := strings.Split(buf.String(), "\n")
+	fmt.Println(strings.Join(lines[:15], " "))
+	// Output: 1 2 Fizz 4 Buzz Fizz 7 8 Fizz Buzz 11 Fizz 13 14 FizzBuzz
+}
+
+func ExampleBuffered() {
+	var buf bytes.Buffer
+
+	Buffered(&buf, 4096, BranchlessTo)
+
+	fmt.Println(strings.Count(buf.String(), "FizzBuzz"))
+	// Output: 6
+}
@@ -0,0 +1,25 @@
+package prime
+
+import "math"
+
+// smallPrimes are the first few primes, for bounds the formula can't handle
+var smallPrimes = [...]int{2, 3, 5, 7, 11}
+
+// UpperBound returns a value that is at least as big as the count-th prime.
+// Use it to size a sieve when you know how many primes you want.
+//
+// For count >= 6, p(count) < count * (ln count + ln ln count) (Rosser's theorem).
+func UpperBound(count int) int {
+	if count < 1 {
+		return 0
+	}
+
+	if count <= len(smallPrimes) {
+		return smallPrimes[count-1]
+	}
+
+	var n = float64(count)
+
+	// Round up, and add one for float error
+	return int(n*(math.Log(n)+math.Log(math.Log(n)))) + 1
+}
@@ -0,0 +1,163 @@
+package prime
+
+import "math/big"
+
+var (
+	bigOne = big.NewInt(1)
+	bigTwo = big.NewInt(2)
+)
+
+// IsPrimeBig reports whether n is probably prime using the Baillie-PSW test.
+//
+// Baillie-PSW is a Miller-Rabin round with base 2 followed by a strong
+// Lucas test. No number is known to pass both without being prime, and
+// there are none below 2**64.
+func IsPrimeBig(n *big.Int) bool {
+	// Small enough for the exact test
+	if n.IsUint64() {
+		return IsPrime(n.Uint64())
+	}
+
+	// Negative, or too big for a uint64 but even
+	if n.Sign() <= 0 || n.Bit(0) == 0 {
+		return false
+	}
+
+	// Knock out small factors
+	var r = new(big.Int)
+	for _, p := range millerRabinBases {
+		if r.Mod(n, r.SetUint64(p)).Sign() == 0 {
+			return false
+		}
+	}
+
+	return millerRabinBig(n, bigTwo) && strongLucas(n)
+}
+
+// millerRabinBig runs one round of Miller-Rabin on an odd n with base a
+func millerRabinBig(n, a *big.Int) bool {
+	var nm1 = new(big.Int).Sub(n, bigOne)
+
+	// Write n-1 as d * 2**s with d odd
+	var s = nm1.TrailingZeroBits()
+	var d = new(big.Int).Rsh(nm1, s)
+
+	var x = new(big.Int).Exp(a, d, n)
+
+	if x.Cmp(bigOne) == 0 || x.Cmp(nm1) == 0 {
+		return true
+	}
+
+	for i := uint(1); i < s; i++ {
+		x.Mul(x, x).Mod(x, n)
+
+		if x.Cmp(nm1) == 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// strongLucas runs a strong Lucas probable prime test on an odd n, using
+// Selfridge's method to pick the parameters.
+//
+// Find the first D in 5, -7, 9, -11, ... with Jacobi(D/n) = -1. Then P = 1
+// and Q = (1-D)/4. With n+1 = d * 2**s and d odd, n is a strong Lucas
+// probable prime if U(d) = 0 or V(d * 2**r) = 0 for some 0 <= r < s.
+func strongLucas(n *big.Int) bool {
+	// A perfect square never gives Jacobi -1, so the search below would
+	// never end
+	var root = new(big.Int).Sqrt(n)
+	if root.Mul(root, root).Cmp(n) == 0 {
+		return false
+	}
+
+	var dd int64 = 5
+	var bigD = new(big.Int)
+
+	for {
+		bigD.SetInt64(dd)
+
+		var j = big.Jacobi(bigD, n)
+
+		if j == -1 {
+			break
+		}
+
+		// D shares a factor with n
+		if j == 0 && new(big.Int).Abs(bigD).Cmp(n) != 0 {
+			return false
+		}
+
+		// 5, -7, 9, -11, ...
+		if dd > 0 {
+			dd = -dd - 2
+		} else {
+			dd = -dd + 2
+		}
+	}
+
+	var q = big.NewInt((1 - dd) / 4)
+
+	// n+1 = d * 2**s
+	var np1 = new(big.Int).Add(n, bigOne)
+	var s = np1.TrailingZeroBits()
+	var d = new(big.Int).Rsh(np1, s)
+
+	// Walk the bits of d from the top. k starts at 1: U=1, V=P=1, Q**k=Q
+	var u = big.NewInt(1)
+	var v = big.NewInt(1)
+	var qk = new(big.Int).Mod(q, n)
+	var t = new(big.Int)
+
+	for i := d.BitLen() - 2; i >= 0; i-- {
+		// k -> 2k
+		// U(2k) = U(k) V(k)
+		// V(2k) = V(k)**2 - 2 Q**k
+		u.Mul(u, v).Mod(u, n)
+		v.Mul(v, v).Sub(v, t.Lsh(qk, 1)).Mod(v, n)
+		qk.Mul(qk, qk).Mod(qk, n)
+
+		if d.Bit(i) == 1 {
+			// 2k -> 2k+1, with P = 1
+			// U(k+1) = (U(k) + V(k)) / 2
+			// V(k+1) = (D U(k) + V(k)) / 2
+			t.Mul(bigD, u)
+			u.Add(u, v)
+			v.Add(v, t)
+			halfMod(u, n)
+			halfMod(v, n)
+			qk.Mul(qk, q).Mod(qk, n)
+		}
+	}
+
+	if u.Sign() == 0 || v.Sign() == 0 {
+		return true
+	}
+
+	// V(2k) = V(k)**2 - 2 Q**k, looking for a zero
+	for r := uint(1); r < s; r++ {
+		v.Mul(v, v).Sub(v, t.Lsh(qk, 1)).Mod(v, n)
+
+		if v.Sign() == 0 {
+			return true
+		}
+
+		qk.Mul(qk, qk).Mod(qk, n)
+	}
+
+	return false
+}
+
+// halfMod sets x to x/2 mod n, for odd n
+func halfMod(x, n *big.Int) {
+	x.Mod(x, n)
+
+	// Make x even without changing it mod n
+	if x.Bit(0) == 1 {
+		x.Add(x, n)
+	}
+
+	x.Rsh(x, 1)
+}
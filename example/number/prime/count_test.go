@@ -0,0 +1,47 @@
+package prime
+
+import "testing"
+
+func TestCount(t *testing.T) {
+	primes := Sieve(1000000)
+
+	var idx int
+	for x := 0; x <= 1000000; x++ {
+		for idx < len(primes) && primes[idx] <= x {
+			idx++
+		}
+
+		// Every value is slow. Check all the small ones and a spread after
+		if x > 10000 && x%997 != 0 {
+			continue
+		}
+
+		if got, err := Count(uint64(x)); err != nil || got != uint64(idx) {
+			t.Fatalf("Count(%d): expected %d, got %d (%v)", x, idx, got, err)
+		}
+	}
+}
+
+func TestCountLarge(t *testing.T) {
+	tests := []struct {
+		x, pi uint64
+	}{
+		{1000000000, 50847534},
+		{10000000000, 455052511},
+		{4294967295, 203280221},
+	}
+
+	for _, test := range tests {
+		if got, err := Count(test.x); err != nil || got != test.pi {
+			t.Errorf("Count(%d): expected %d, got %d (%v)", test.x, test.pi, got, err)
+		}
+	}
+}
+
+func TestCountLimit(t *testing.T) {
+	for _, x := range []uint64{MaxCount + 1, 1 << 63, 1<<64 - 1} {
+		if _, err := Count(x); err != errorTooLarge {
+			t.Errorf("Count(%d): expected %v, got %v", x, errorTooLarge, err)
+		}
+	}
+}
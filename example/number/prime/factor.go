@@ -0,0 +1,264 @@
+package prime
+
+import (
+	"math/bits"
+	"sort"
+	"sync"
+)
+
+// trialLimit is the largest number we trial divide by before handing off
+// to Pollard's rho
+const trialLimit = 1 << 12
+
+// trialPrimes are the primes up to trialLimit, built on first use
+var trialPrimes struct {
+	once   sync.Once
+	primes []int
+}
+
+// Factor is a prime factor and how many times it divides a number
+type Factor struct {
+	Prime    uint64
+	Exponent int
+}
+
+// Factorize returns the prime factors of n in ascending order, with their
+// multiplicities. Factorize(0) and Factorize(1) return nil.
+//
+// Small factors are found by trial division by the primes up to 4096.
+// Whatever is left is split with Pollard's rho (Brent's variant) until every
+// piece passes IsPrime.
+func Factorize(n uint64) []Factor {
+	if n < 2 {
+		return nil
+	}
+
+	var factors []Factor
+
+	trialPrimes.once.Do(func() {
+		trialPrimes.primes = Sieve(trialLimit)
+	})
+
+	// Trial division
+	for _, p := range trialPrimes.primes {
+		var pp = uint64(p)
+
+		// No factor left below sqrt(n), so what remains is prime
+		if pp*pp > n {
+			break
+		}
+
+		var exp int
+		for n%pp == 0 {
+			n /= pp
+			exp++
+		}
+
+		if exp > 0 {
+			factors = append(factors, Factor{Prime: pp, Exponent: exp})
+		}
+	}
+
+	if n == 1 {
+		return factors
+	}
+
+	// Split whatever is left into primes. Order and count them after
+	var rest = splitPrimes(n, nil)
+
+	sort.Slice(rest, func(i, j int) bool { return rest[i] < rest[j] })
+
+	for _, p := range rest {
+		if last := len(factors) - 1; last >= 0 && factors[last].Prime == p {
+			factors[last].Exponent++
+			continue
+		}
+
+		factors = append(factors, Factor{Prime: p, Exponent: 1})
+	}
+
+	return factors
+}
+
+// Divisors returns every divisor of n in ascending order, including 1 and n.
+// Divisors(0) returns nil.
+func Divisors(n uint64) []uint64 {
+	if n == 0 {
+		return nil
+	}
+
+	var divisors = []uint64{1}
+
+	// For each prime power, multiply it into every divisor found so far
+	for _, f := range Factorize(n) {
+		var count = len(divisors)
+		var pk uint64 = 1
+
+		for e := 0; e < f.Exponent; e++ {
+			pk *= f.Prime
+
+			for _, d := range divisors[:count] {
+				divisors = append(divisors, d*pk)
+			}
+		}
+	}
+
+	sort.Slice(divisors, func(i, j int) bool { return divisors[i] < divisors[j] })
+
+	return divisors
+}
+
+// EulerPhi returns the number of integers in [1, n] that share no factor
+// with n. EulerPhi(0) returns 0.
+func EulerPhi(n uint64) uint64 {
+	if n == 0 {
+		return 0
+	}
+
+	var phi = n
+
+	// phi(n) = n * prod(1 - 1/p). Divide first so we never overflow
+	for _, f := range Factorize(n) {
+		phi = phi / f.Prime * (f.Prime - 1)
+	}
+
+	return phi
+}
+
+// IsSquareFree reports whether no prime divides n more than once.
+// IsSquareFree(0) returns false.
+func IsSquareFree(n uint64) bool {
+	if n == 0 {
+		return false
+	}
+
+	for _, f := range Factorize(n) {
+		if f.Exponent > 1 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// splitPrimes appends the prime factors of n to primes, with repeats.
+// n must have no factors up to trialLimit.
+func splitPrimes(n uint64, primes []uint64) []uint64 {
+	if n == 1 {
+		return primes
+	}
+
+	if IsPrime(n) {
+		return append(primes, n)
+	}
+
+	// Perfect squares are common and rho is slow on them. Catch them here
+	if r := isqrt64(n); r*r == n {
+		primes = splitPrimes(r, primes)
+		return splitPrimes(r, primes)
+	}
+
+	// Keep trying new constants until rho finds a proper factor
+	var d = n
+	for c := uint64(1); d == n; c++ {
+		d = pollardBrent(n, c)
+	}
+
+	primes = splitPrimes(d, primes)
+
+	return splitPrimes(n/d, primes)
+}
+
+// pollardBrent looks for a factor of n using Pollard's rho with Brent's
+// cycle detection, iterating x -> x*x + c mod n.
+//
+// Differences are multiplied together and checked with one gcd per batch,
+// which saves most of the gcd calls. If a batch overshoots (the gcd is n),
+// the batch is replayed one step at a time.
+//
+// It returns n if it failed, in which case try another c.
+func pollardBrent(n, c uint64) uint64 {
+	const batch = 128
+
+	var f = func(x uint64) uint64 {
+		return addMod(mulMod(x, x, n), c, n)
+	}
+
+	var x, y, ys uint64 = 0, 2, 0
+	var g, q, r uint64 = 1, 1, 1
+
+	for g == 1 {
+		x = y
+
+		// Move y ahead r steps
+		for i := uint64(0); i < r; i++ {
+			y = f(y)
+		}
+
+		for k := uint64(0); k < r && g == 1; k += batch {
+			ys = y
+
+			for i := uint64(0); i < batch && i < r-k; i++ {
+				y = f(y)
+				q = mulMod(q, absDiff(x, y), n)
+			}
+
+			g = gcd(q, n)
+		}
+
+		r <<= 1
+	}
+
+	// Overshot. Go back to the start of the batch and step through it
+	if g == n {
+		for g = 1; g == 1; {
+			ys = f(ys)
+			g = gcd(absDiff(x, ys), n)
+		}
+	}
+
+	return g
+}
+
+// addMod returns a+b mod m, for a, b < m, without overflowing
+func addMod(a, b, m uint64) uint64 {
+	var sum, carry = bits.Add64(a, b, 0)
+
+	if carry != 0 || sum >= m {
+		sum -= m
+	}
+
+	return sum
+}
+
+// absDiff returns |a-b|
+func absDiff(a, b uint64) uint64 {
+	if a > b {
+		return a - b
+	}
+
+	return b - a
+}
+
+// gcd returns the greatest common divisor of a and b
+func gcd(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+
+	return a
+}
+
+// isqrt64 returns the largest r with r*r <= n
+func isqrt64(n uint64) uint64 {
+	var r uint64
+
+	// Build the root one bit at a time, from the top
+	for bit := uint64(1) << 31; bit > 0; bit >>= 1 {
+		if c := r | bit; c <= n/c {
+			r = c
+		}
+	}
+
+	return r
+}
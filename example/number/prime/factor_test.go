@@ -0,0 +1,114 @@
+package prime
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// checkFactors makes sure factors multiply back to n, are prime and ascend
+func checkFactors(t *testing.T, n uint64, factors []Factor) {
+	t.Helper()
+
+	var product uint64 = 1
+	var last uint64
+
+	for _, f := range factors {
+		if !IsPrime(f.Prime) {
+			t.Fatalf("Factorize(%d): %d is not prime", n, f.Prime)
+		}
+
+		if f.Prime <= last {
+			t.Fatalf("Factorize(%d): factors out of order %v", n, factors)
+		}
+
+		if f.Exponent < 1 {
+			t.Fatalf("Factorize(%d): bad exponent in %v", n, factors)
+		}
+
+		for e := 0; e < f.Exponent; e++ {
+			product *= f.Prime
+		}
+
+		last = f.Prime
+	}
+
+	if product != n {
+		t.Fatalf("Factorize(%d): factors %v multiply to %d", n, factors, product)
+	}
+}
+
+func TestFactorizeSmall(t *testing.T) {
+	if Factorize(0) != nil || Factorize(1) != nil {
+		t.Error("expected no factors for 0 and 1")
+	}
+
+	for n := uint64(2); n <= 100000; n++ {
+		checkFactors(t, n, Factorize(n))
+	}
+}
+
+func TestFactorizeLarge(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	tests := []uint64{
+		4294967297,           // 641 * 6700417
+		18446744073709551615, // 3 * 5 * 17 * 257 * 641 * 65537 * 6700417
+		18446744073709551557, // prime
+		4611686014132420609,  // (2**31 - 1)**2
+		1000000016000000063,  // 1000000007 * 1000000009
+	}
+
+	for i := 0; i < 200; i++ {
+		tests = append(tests, rnd.Uint64())
+	}
+
+	for i := 0; i < 50; i++ {
+		p := NextPrime(rnd.Uint64() >> 32)
+		q := NextPrime(rnd.Uint64() >> 32)
+		tests = append(tests, p*q)
+	}
+
+	for _, n := range tests {
+		checkFactors(t, n, Factorize(n))
+	}
+}
+
+func TestDivisorHelpers(t *testing.T) {
+	for n := uint64(1); n <= 3000; n++ {
+		var divisors []uint64
+		var phi uint64
+		squareFree := true
+
+		for d := uint64(1); d <= n; d++ {
+			if n%d == 0 {
+				divisors = append(divisors, d)
+				if d > 1 && n%(d*d) == 0 {
+					squareFree = false
+				}
+			}
+
+			if gcd(n, d) == 1 {
+				phi++
+			}
+		}
+
+		got := Divisors(n)
+		if len(got) != len(divisors) {
+			t.Fatalf("Divisors(%d): expected %v, got %v", n, divisors, got)
+		}
+
+		for i := range got {
+			if got[i] != divisors[i] {
+				t.Fatalf("Divisors(%d): expected %v, got %v", n, divisors, got)
+			}
+		}
+
+		if got := EulerPhi(n); got != phi {
+			t.Fatalf("EulerPhi(%d): expected %d, got %d", n, phi, got)
+		}
+
+		if got := IsSquareFree(n); got != squareFree {
+			t.Fatalf("IsSquareFree(%d): expected %t, got %t", n, squareFree, got)
+		}
+	}
+}
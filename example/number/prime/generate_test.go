@@ -0,0 +1,126 @@
+package prime
+
+import (
+	"fmt"
+	"testing"
+)
+
+// naive checks n by trial division against every number up to sqrt(n)
+func naive(n int) bool {
+	if n < 2 {
+		return false
+	}
+
+	for d := 2; d*d <= n; d++ {
+		if n%d == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func naiveUpTo(n int) []int {
+	var primes []int
+	for i := 2; i <= n; i++ {
+		if naive(i) {
+			primes = append(primes, i)
+		}
+	}
+	return primes
+}
+
+func equal(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSieves(t *testing.T) {
+	sieves := map[string]func(int) []int{
+		"Sieve":          Sieve,
+		"SegmentedSieve": SegmentedSieve,
+		"WheelSieve":     WheelSieve,
+		"GenerateUpTo":   GenerateUpTo,
+	}
+
+	for _, n := range []int{0, 1, 2, 3, 4, 5, 6, 7, 29, 30, 31, 100, 961, 65536, 100003} {
+		expect := naiveUpTo(n)
+
+		for name, sieve := range sieves {
+			if got := sieve(n); !equal(got, expect) {
+				t.Errorf("%s(%d): expected %d primes, got %d", name, n, len(expect), len(got))
+			}
+		}
+	}
+}
+
+func TestGenerateCount(t *testing.T) {
+	for _, count := range []int{0, 1, 2, 5, 6, 10, 100, 1000} {
+		got := GenerateCount(count)
+		expect := TrialDivision(count)
+
+		if !equal(got, expect) {
+			t.Errorf("GenerateCount(%d): expected %v, got %v", count, expect, got)
+		}
+	}
+}
+
+func TestUpperBound(t *testing.T) {
+	primes := Sieve(2000000)
+
+	for count := 1; count <= len(primes); count++ {
+		if bound := UpperBound(count); bound < primes[count-1] {
+			t.Fatalf("UpperBound(%d) = %d, below prime %d", count, bound, primes[count-1])
+		}
+	}
+}
+
+func BenchmarkGenerateCount(b *testing.B) {
+	impls := []struct {
+		name string
+		fn   func(int) []int
+	}{
+		{"TrialDivision", TrialDivision},
+		{"GenerateCount", GenerateCount},
+	}
+
+	for _, count := range []int{100, 1000, 10000} {
+		for _, impl := range impls {
+			b.Run(fmt.Sprintf("%s/%d", impl.name, count), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					impl.fn(count)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkUpTo(b *testing.B) {
+	impls := []struct {
+		name string
+		fn   func(int) []int
+	}{
+		{"Sieve", Sieve},
+		{"SegmentedSieve", SegmentedSieve},
+		{"WheelSieve", WheelSieve},
+	}
+
+	for _, n := range []int{1000, 1000000, 10000000} {
+		for _, impl := range impls {
+			b.Run(fmt.Sprintf("%s/%d", impl.name, n), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					impl.fn(n)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package prime
+
+import "math/bits"
+
+// millerRabinBases are enough to make Miller-Rabin exact for every 64-bit
+// number. The first twelve primes work for n < 3.3 * 10**24.
+var millerRabinBases = [...]uint64{2, 3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37}
+
+// IsPrime reports whether n is prime.
+//
+// Small factors are ruled out by trial division. Anything left goes through
+// Miller-Rabin with a fixed set of bases, which has no false positives
+// below 2**64, so the answer is exact.
+//
+// Time: O(log**3 n)
+func IsPrime(n uint64) bool {
+	if n < 2 {
+		return false
+	}
+
+	// Knock out the easy ones
+	for _, p := range millerRabinBases {
+		if n == p {
+			return true
+		}
+
+		if n%p == 0 {
+			return false
+		}
+	}
+
+	// Anything this small with no factor up to 37 is prime
+	if n < 41*41 {
+		return true
+	}
+
+	// Write n-1 as d * 2**s with d odd
+	var d = n - 1
+	var s = bits.TrailingZeros64(d)
+	d >>= uint(s)
+
+	for _, a := range millerRabinBases {
+		if !strongProbablePrime(n, d, s, a) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NextPrime returns the smallest prime greater than n.
+// Returns 0 if there is no such prime that fits in a uint64.
+func NextPrime(n uint64) uint64 {
+	if n < 2 {
+		return 2
+	}
+
+	// Move to the next odd number, stepping 2 at a time from there
+	var c = n + 1 + (n & 1)
+
+	// c wraps around to a small number once we run off the end
+	for ; c > n; c += 2 {
+		if IsPrime(c) {
+			return c
+		}
+	}
+
+	return 0
+}
+
+// PrevPrime returns the largest prime less than n.
+// Returns 0 if n <= 2.
+func PrevPrime(n uint64) uint64 {
+	if n <= 3 {
+		if n == 3 {
+			return 2
+		}
+
+		return 0
+	}
+
+	// Move to the previous odd number, stepping 2 at a time from there
+	for c := n - 1 - (n & 1); c > 2; c -= 2 {
+		if IsPrime(c) {
+			return c
+		}
+	}
+
+	return 2
+}
+
+// strongProbablePrime runs one round of Miller-Rabin on n with base a,
+// where n-1 = d * 2**s and d is odd.
+func strongProbablePrime(n, d uint64, s int, a uint64) bool {
+	var x = powMod(a%n, d, n)
+
+	if x == 1 || x == n-1 {
+		return true
+	}
+
+	// Square up to s-1 times, looking for -1
+	for r := 1; r < s; r++ {
+		x = mulMod(x, x, n)
+
+		if x == n-1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mulMod returns a*b mod m without overflowing
+func mulMod(a, b, m uint64) uint64 {
+	var hi, lo = bits.Mul64(a, b)
+
+	return bits.Rem64(hi, lo, m)
+}
+
+// powMod returns b**e mod m by squaring
+func powMod(b, e, m uint64) uint64 {
+	var result uint64 = 1
+
+	for ; e > 0; e >>= 1 {
+		if e&1 == 1 {
+			result = mulMod(result, b, m)
+		}
+
+		b = mulMod(b, b, m)
+	}
+
+	return result
+}
@@ -0,0 +1,139 @@
+package prime
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestIsPrime(t *testing.T) {
+	primes := GenerateCount(20000)
+	limit := primes[len(primes)-1]
+
+	var idx int
+	for n := 0; n <= limit; n++ {
+		expect := idx < len(primes) && primes[idx] == n
+		if expect {
+			idx++
+		}
+
+		if got := IsPrime(uint64(n)); got != expect {
+			t.Fatalf("IsPrime(%d): expected %t, got %t", n, expect, got)
+		}
+	}
+}
+
+func TestIsPrimeLarge(t *testing.T) {
+	tests := []struct {
+		n     uint64
+		prime bool
+	}{
+		{2047, false},                // strong pseudoprime to base 2
+		{3215031751, false},          // strong pseudoprime to bases 2, 3, 5, 7
+		{3825123056546413051, false}, // strong pseudoprime to bases up to 23
+		{4294967291, true},           // largest prime below 2**32
+		{4294967297, false},          // 2**32 + 1 = 641 * 6700417
+		{2305843009213693951, true},  // 2**61 - 1
+		{18446744073709551557, true}, // largest prime below 2**64
+		{math.MaxUint64, false},
+	}
+
+	for _, test := range tests {
+		if got := IsPrime(test.n); got != test.prime {
+			t.Errorf("IsPrime(%d): expected %t, got %t", test.n, test.prime, got)
+		}
+	}
+}
+
+func TestNextPrevPrime(t *testing.T) {
+	primes := GenerateCount(2000)
+
+	for i := 1; i < len(primes); i++ {
+		for n := primes[i-1]; n < primes[i]; n++ {
+			if got := NextPrime(uint64(n)); got != uint64(primes[i]) {
+				t.Fatalf("NextPrime(%d): expected %d, got %d", n, primes[i], got)
+			}
+		}
+
+		for n := primes[i-1] + 1; n <= primes[i]; n++ {
+			if got := PrevPrime(uint64(n)); got != uint64(primes[i-1]) {
+				t.Fatalf("PrevPrime(%d): expected %d, got %d", n, primes[i-1], got)
+			}
+		}
+	}
+
+	if got := NextPrime(0); got != 2 {
+		t.Errorf("NextPrime(0): expected %d, got %d", 2, got)
+	}
+
+	if got := PrevPrime(2); got != 0 {
+		t.Errorf("PrevPrime(2): expected %d, got %d", 0, got)
+	}
+
+	if got := NextPrime(18446744073709551557); got != 0 {
+		t.Errorf("NextPrime past the last 64-bit prime: expected %d, got %d", 0, got)
+	}
+
+	if got := PrevPrime(math.MaxUint64); got != 18446744073709551557 {
+		t.Errorf("PrevPrime(MaxUint64): expected %d, got %d", uint64(18446744073709551557), got)
+	}
+}
+
+func TestStrongLucas(t *testing.T) {
+	// Strong Lucas pseudoprimes with Selfridge parameters. These are
+	// composite, but the Lucas test alone lets them through
+	for _, n := range []int64{5459, 5777, 10877, 16109, 18971} {
+		if !strongLucas(big.NewInt(n)) {
+			t.Errorf("strongLucas(%d): expected pseudoprime to pass", n)
+		}
+	}
+
+	for _, p := range GenerateCount(500)[1:] {
+		if !strongLucas(big.NewInt(int64(p))) {
+			t.Errorf("strongLucas(%d): expected prime to pass", p)
+		}
+	}
+}
+
+func TestIsPrimeBig(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	// Mersenne primes and their neighbours
+	for _, exp := range []uint{61, 89, 107, 127, 521, 607} {
+		n := new(big.Int).Lsh(big.NewInt(1), exp)
+		n.Sub(n, big.NewInt(1))
+
+		if !IsPrimeBig(n) {
+			t.Errorf("IsPrimeBig(2**%d - 1): expected prime", exp)
+		}
+
+		if IsPrimeBig(n.Add(n, big.NewInt(2))) {
+			t.Errorf("IsPrimeBig(2**%d + 1): expected composite", exp)
+		}
+	}
+
+	// Products of two primes
+	for i := 0; i < 50; i++ {
+		p := new(big.Int).SetUint64(NextPrime(rnd.Uint64()))
+		q := new(big.Int).SetUint64(NextPrime(rnd.Uint64()))
+
+		if IsPrimeBig(new(big.Int).Mul(p, q)) {
+			t.Errorf("IsPrimeBig(%v * %v): expected composite", p, q)
+		}
+	}
+
+	// Random odd numbers, checked against the standard library
+	for i := 0; i < 2000; i++ {
+		n := new(big.Int).Rand(rnd, new(big.Int).Lsh(big.NewInt(1), 100))
+		n.SetBit(n, 0, 1)
+
+		if got, expect := IsPrimeBig(n), n.ProbablyPrime(20); got != expect {
+			t.Errorf("IsPrimeBig(%v): expected %t, got %t", n, expect, got)
+		}
+	}
+
+	if IsPrimeBig(big.NewInt(-7)) {
+		t.Error("IsPrimeBig(-7): expected false")
+	}
+}
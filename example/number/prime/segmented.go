@@ -0,0 +1,82 @@
+package prime
+
+// segmentSize is the width of each segment, in numbers. 32KiB of flags
+// fits comfortably in L1 cache.
+const segmentSize = 1 << 15
+
+// SegmentedSieve returns every prime <= n using a segmented sieve of
+// Eratosthenes.
+//
+// First the primes up to sqrt(n) are found with a plain sieve. Then the
+// range is walked one segment at a time, crossing off multiples of those
+// primes in a small buffer that is reused for every segment.
+//
+// Time: O(n log log n)
+// Space: O(sqrt(n) + segmentSize) working memory, plus the result
+func SegmentedSieve(n int) []int {
+	if n < 2 {
+		return nil
+	}
+
+	var root = isqrt(n)
+	var base = Sieve(root)
+
+	var primes = make([]int, len(base), estimateCount(n))
+	copy(primes, base)
+
+	// next[i] is the next multiple of base[i] still to cross off
+	var next = make([]int, len(base))
+	for i, p := range base {
+		next[i] = p * p
+	}
+
+	var composite = make([]bool, segmentSize)
+	var low, high, i, m int
+
+	for low = root + 1; low <= n; low += segmentSize {
+		high = low + segmentSize - 1
+		if high > n {
+			high = n
+		}
+
+		// Reset the buffer for this segment
+		for i = range composite {
+			composite[i] = false
+		}
+
+		for i = range base {
+			// Bring the first multiple up into this segment
+			if next[i] < low {
+				next[i] += (low - next[i] + base[i] - 1) / base[i] * base[i]
+			}
+
+			for m = next[i]; m <= high; m += base[i] {
+				composite[m-low] = true
+			}
+
+			next[i] = m
+		}
+
+		for m = low; m <= high; m++ {
+			if !composite[m-low] {
+				primes = append(primes, m)
+			}
+		}
+	}
+
+	return primes
+}
+
+// isqrt returns the largest r with r*r <= n
+func isqrt(n int) int {
+	var r = 0
+
+	// Build the root one bit at a time, from the top
+	for bit := 1 << 31; bit > 0; bit >>= 1 {
+		if c := r | bit; c <= n/c {
+			r = c
+		}
+	}
+
+	return r
+}
@@ -0,0 +1,52 @@
+package prime
+
+import "math"
+
+// Sieve returns every prime <= n using the sieve of Eratosthenes.
+//
+// Only odd numbers are kept in the sieve. Index i stands for 2i+1.
+// For each prime p we cross off p*p, p*p+2p, p*p+4p, ... as everything
+// smaller was already crossed off by a smaller prime.
+//
+// Time: O(n log log n)
+// Space: O(n)
+func Sieve(n int) []int {
+	if n < 2 {
+		return nil
+	}
+
+	// composite[i] is true when 2i+1 is not prime
+	var composite = make([]bool, n/2+1)
+	var primes = make([]int, 1, estimateCount(n))
+	primes[0] = 2
+
+	var i, p, m int
+
+	for i = 1; 2*i+1 <= n; i++ {
+		if composite[i] {
+			continue
+		}
+
+		p = 2*i + 1
+		primes = append(primes, p)
+
+		// Step by 2p so we only ever touch odd multiples
+		for m = p * p; m <= n; m += 2 * p {
+			composite[m/2] = true
+		}
+	}
+
+	return primes
+}
+
+// estimateCount returns a guess for the number of primes <= n, to size
+// result slices. pi(n) < 1.26 n / ln n for n > 1, so it is rarely short.
+func estimateCount(n int) int {
+	if n < 17 {
+		return 8
+	}
+
+	var x = float64(n)
+
+	return int(1.26*x/math.Log(x)) + 1
+}
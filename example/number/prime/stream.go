@@ -0,0 +1,213 @@
+package prime
+
+import (
+	"context"
+	"sync"
+)
+
+// Iterator hands out primes in order, one at a time, with no upper limit.
+//
+// It is an incremental segmented sieve. Primes are sieved a segment at a
+// time, using sieving primes pulled from a second Iterator as the segments
+// climb. Memory grows with the square root of the largest prime returned.
+//
+// An Iterator is not safe for concurrent use.
+type Iterator struct {
+	buf  []uint64 // primes from the current segment
+	pos  int      // next prime in buf to return
+	low  uint64   // start of the next segment
+	base *baseSet // sieving primes
+}
+
+// NewIterator returns an Iterator starting at 2
+func NewIterator() *Iterator {
+	return &Iterator{}
+}
+
+// Next returns the next prime
+func (it *Iterator) Next() uint64 {
+	for it.pos == len(it.buf) {
+		it.fill()
+	}
+
+	var p = it.buf[it.pos]
+	it.pos++
+
+	return p
+}
+
+// fill sieves the next segment into buf
+func (it *Iterator) fill() {
+	it.pos = 0
+
+	// The first segment is a plain sieve. It has everything we need to
+	// sieve the rest of the way up to segmentSize**2
+	if it.low == 0 {
+		it.buf = firstSegment()
+		it.low = segmentSize
+		return
+	}
+
+	if it.base == nil {
+		it.base = newBaseSet()
+	}
+
+	var high = it.low + segmentSize - 1
+
+	it.buf = sieveSegment(it.low, high, it.base.upTo(high), it.buf[:0])
+	it.low = high + 1
+}
+
+// Primes returns a channel that receives every prime in order, until ctx is
+// done. The channel is closed once the generator stops.
+func Primes(ctx context.Context) <-chan uint64 {
+	var out = make(chan uint64)
+
+	go func() {
+		defer close(out)
+
+		var it = NewIterator()
+
+		for {
+			select {
+			case out <- it.Next():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// PrimesParallel is Primes with the sieving spread over workers goroutines.
+//
+// Each round, the next workers segments are sieved at the same time, one
+// per goroutine. The results are sent in order once the round is done.
+func PrimesParallel(ctx context.Context, workers int) <-chan uint64 {
+	if workers < 1 {
+		panic("Value less than 1 for workers provided")
+	}
+
+	var out = make(chan uint64, segmentSize)
+
+	go func() {
+		defer close(out)
+
+		// send pushes primes out, returning false if we should stop
+		var send = func(primes []uint64) bool {
+			for _, p := range primes {
+				select {
+				case out <- p:
+				case <-ctx.Done():
+					return false
+				}
+			}
+
+			return true
+		}
+
+		if !send(firstSegment()) {
+			return
+		}
+
+		var base = newBaseSet()
+		var results = make([][]uint64, workers)
+		var low uint64 = segmentSize
+		var wg sync.WaitGroup
+
+		for {
+			var high = low + uint64(workers)*segmentSize - 1
+			var sieving = base.upTo(high)
+
+			wg.Add(workers)
+
+			for w := 0; w < workers; w++ {
+				go func(w int) {
+					defer wg.Done()
+
+					var segLow = low + uint64(w)*segmentSize
+					results[w] = sieveSegment(segLow, segLow+segmentSize-1, sieving, results[w][:0])
+				}(w)
+			}
+
+			wg.Wait()
+
+			for _, primes := range results {
+				if !send(primes) {
+					return
+				}
+			}
+
+			low = high + 1
+		}
+	}()
+
+	return out
+}
+
+// baseSet is a growing list of sieving primes
+type baseSet struct {
+	it     *Iterator // where more primes come from
+	primes []uint64  // sieving primes so far
+	next   uint64    // prime pulled from it but not needed yet
+}
+
+// newBaseSet returns an empty baseSet
+func newBaseSet() *baseSet {
+	var b = &baseSet{it: NewIterator()}
+
+	b.next = b.it.Next()
+
+	return b
+}
+
+// upTo returns every prime p with p*p <= high
+func (b *baseSet) upTo(high uint64) []uint64 {
+	for b.next <= high/b.next {
+		b.primes = append(b.primes, b.next)
+		b.next = b.it.Next()
+	}
+
+	return b.primes
+}
+
+// firstSegment returns the primes below segmentSize
+func firstSegment() []uint64 {
+	var small = Sieve(segmentSize - 1)
+	var primes = make([]uint64, len(small))
+
+	for i, p := range small {
+		primes[i] = uint64(p)
+	}
+
+	return primes
+}
+
+// sieveSegment appends the primes in [low, high] to primes.
+// sieving must hold every prime up to sqrt(high), and low must be bigger
+// than all of them.
+func sieveSegment(low, high uint64, sieving []uint64, primes []uint64) []uint64 {
+	var composite = make([]bool, high-low+1)
+
+	for _, p := range sieving {
+		// First multiple of p in the segment. Everything below p*p was
+		// crossed off by a smaller prime
+		var m = (low + p - 1) / p * p
+		if sq := p * p; m < sq {
+			m = sq
+		}
+
+		for ; m <= high; m += p {
+			composite[m-low] = true
+		}
+	}
+
+	for i, c := range composite {
+		if !c {
+			primes = append(primes, low+uint64(i))
+		}
+	}
+
+	return primes
+}
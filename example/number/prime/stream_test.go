@@ -0,0 +1,71 @@
+package prime
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestIterator(t *testing.T) {
+	expect := GenerateCount(200000)
+	it := NewIterator()
+
+	for i, p := range expect {
+		if got := it.Next(); got != uint64(p) {
+			t.Fatalf("prime %d: expected %d, got %d", i, p, got)
+		}
+	}
+}
+
+func TestPrimesChannels(t *testing.T) {
+	expect := GenerateCount(100000)
+
+	streams := map[string]func(context.Context) <-chan uint64{
+		"Primes": Primes,
+		"PrimesParallel": func(ctx context.Context) <-chan uint64 {
+			return PrimesParallel(ctx, 4)
+		},
+	}
+
+	for name, stream := range streams {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			ch := stream(ctx)
+
+			for i, p := range expect {
+				if got := <-ch; got != uint64(p) {
+					t.Fatalf("prime %d: expected %d, got %d", i, p, got)
+				}
+			}
+
+			cancel()
+
+			// Drain whatever was in flight. The channel must close
+			for range ch {
+			}
+		})
+	}
+}
+
+func ExamplePrimes() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Take primes until one ends in 9
+	for p := range Primes(ctx) {
+		fmt.Println(p)
+
+		if p%10 == 9 {
+			break
+		}
+	}
+	// Output:
+	// 2
+	// 3
+	// 5
+	// 7
+	// 11
+	// 13
+	// 17
+	// 19
+}
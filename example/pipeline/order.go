@@ -0,0 +1,135 @@
+package pipeline
+
+import "sync"
+
+// emitter sends a stage's results on to the next pipe.
+//
+// In ordered mode it is a reorder buffer. Workers finish out of order, so
+// results are held until every earlier result has been sent. Dropped values
+// (from Filter) still take up their place in line so nothing waits on them.
+// Outgoing items are numbered afresh, so the next stage sees no gaps.
+//
+// The mutex is never held while pushing to the next pipe, which may block.
+// In ordered mode one worker at a time is the sender. It pushes every ready
+// result, while the others park theirs and go back to work. A worker whose
+// result is window or more ahead of the next one due waits for room, so
+// the buffer never holds more than window results.
+type emitter struct {
+	mu      sync.Mutex        // Mutex guarding everything below
+	cond    *sync.Cond        // Wakes workers waiting for room in the buffer
+	out     *pipe             // Where results go
+	ordered bool              // Hold results until their turn
+	window  uint64            // Most results held in the buffer
+	sending bool              // A worker is pushing ready results
+	next    uint64            // Next incoming seq we are waiting on
+	seq     uint64            // seq to give the next outgoing item
+	pending map[uint64]result // Results that arrived early
+}
+
+// result is a finished value waiting in the reorder buffer
+type result struct {
+	value interface{}
+	keep  bool
+}
+
+// newEmitter returns an emitter sending to out.
+// window bounds the reorder buffer, and only matters when ordered.
+func newEmitter(out *pipe, ordered bool, window int) *emitter {
+	var e = &emitter{
+		out:     out,
+		ordered: ordered,
+		window:  uint64(window),
+		pending: make(map[uint64]result),
+	}
+
+	e.cond = sync.NewCond(&e.mu)
+
+	return e
+}
+
+// emit sends value along, unless keep is false.
+// seq is the incoming sequence number the value was made from.
+func (e *emitter) emit(seq uint64, value interface{}, keep bool) error {
+	// Unordered. Number it and send it now, if we are keeping it
+	if !e.ordered {
+		if !keep {
+			return nil
+		}
+
+		e.mu.Lock()
+		var it = e.number(value)
+		e.mu.Unlock()
+
+		return e.out.push(it)
+	}
+
+	e.mu.Lock()
+
+	// Too far ahead. Wait for room. The result for next is always let in,
+	// so the buffer keeps draining
+	for seq >= e.next+e.window {
+		if err := e.out.ctx.Err(); err != nil {
+			e.mu.Unlock()
+			return err
+		}
+
+		e.cond.Wait()
+	}
+
+	e.pending[seq] = result{value: value, keep: keep}
+
+	// Someone else is sending. They will pick ours up
+	if e.sending {
+		e.mu.Unlock()
+		return nil
+	}
+
+	e.sending = true
+
+	for {
+		var r, ok = e.pending[e.next]
+		if !ok {
+			e.sending = false
+			e.mu.Unlock()
+			return nil
+		}
+
+		delete(e.pending, e.next)
+		e.next++
+		e.cond.Broadcast()
+
+		if !r.keep {
+			continue
+		}
+
+		var it = e.number(r.value)
+
+		// Others can park results while we wait on the pipe
+		e.mu.Unlock()
+		var err = e.out.push(it)
+		e.mu.Lock()
+
+		if err != nil {
+			e.sending = false
+			e.mu.Unlock()
+			return err
+		}
+	}
+}
+
+// wake wakes any worker waiting for room so it can notice the context is done
+func (e *emitter) wake() {
+	e.mu.Lock()
+	e.cond.Broadcast()
+	e.mu.Unlock()
+}
+
+// number wraps value in an item with a fresh seq.
+// The mutex must be held.
+func (e *emitter) number(value interface{}) item {
+	var it = item{seq: e.seq, value: value}
+
+	e.seq++
+
+	return it
+}
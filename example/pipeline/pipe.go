@@ -0,0 +1,102 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+
+	"github.com/noriah/go-code/structure/queue"
+)
+
+// item is a value moving through the pipeline.
+// seq is its position in the stream, used to put things back in order.
+type item struct {
+	seq   uint64
+	value interface{}
+}
+
+// pipe connects two stages with a queue.
+// The queues in structure/queue never block, so a pipe waits on a condition
+// variable whenever its queue is full (on push) or empty (on pop).
+type pipe struct {
+	mu     sync.Mutex      // Mutex guarding the queue and closed flag
+	cond   *sync.Cond      // Wakes up anyone waiting on the queue
+	queue  queue.Queue     // Queue holding items between stages
+	ctx    context.Context // Stop waiting once this is done
+	closed bool            // No more items will be pushed
+}
+
+// newPipe returns a pipe using q
+func newPipe(ctx context.Context, q queue.Queue) *pipe {
+	var p = &pipe{
+		queue: q,
+		ctx:   ctx,
+	}
+
+	p.cond = sync.NewCond(&p.mu)
+
+	return p
+}
+
+// push adds an item, waiting while the queue is full
+func (p *pipe) push(it item) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for {
+		// Give up if the pipeline is shutting down
+		if err := p.ctx.Err(); err != nil {
+			return err
+		}
+
+		// Try to add the item. An error means the queue is full
+		if p.queue.Push(it) == nil {
+			p.cond.Broadcast()
+			return nil
+		}
+
+		p.cond.Wait()
+	}
+}
+
+// pop removes an item, waiting while the queue is empty.
+// ok is false once the pipe is closed and drained.
+func (p *pipe) pop() (it item, ok bool, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for {
+		// Give up if the pipeline is shutting down
+		if err = p.ctx.Err(); err != nil {
+			return it, false, err
+		}
+
+		// Try to take an item. An error means the queue is empty
+		if value, popErr := p.queue.Pop(); popErr == nil {
+			p.cond.Broadcast()
+			return value.(item), true, nil
+		}
+
+		// Empty and nothing more is coming
+		if p.closed {
+			return it, false, nil
+		}
+
+		p.cond.Wait()
+	}
+}
+
+// close marks the end of the stream.
+// Items already in the pipe can still be popped.
+func (p *pipe) close() {
+	p.mu.Lock()
+	p.closed = true
+	p.cond.Broadcast()
+	p.mu.Unlock()
+}
+
+// wake wakes anyone waiting so they can notice the context is done
+func (p *pipe) wake() {
+	p.mu.Lock()
+	p.cond.Broadcast()
+	p.mu.Unlock()
+}
@@ -0,0 +1,179 @@
+// Package pipeline composes stages into a concurrent pipeline.
+// Values flow from a Source, through any number of Map, Filter and Batch
+// stages, into a Sink. Stages are connected by bounded queues from
+// structure/queue, so a slow stage pushes back on the ones before it.
+//
+// The first error from any stage stops the whole pipeline and is returned
+// from Run. Cancelling the context does the same.
+//
+// In ordered mode, values reach the sink in the order the source emitted
+// them, no matter how many workers a stage has. In unordered mode, values
+// arrive as soon as they are ready.
+package pipeline
+
+import (
+	"context"
+	"sync"
+
+	"github.com/noriah/go-code/structure/queue"
+	"github.com/noriah/go-code/structure/queue/linked"
+)
+
+const defaultQueueSize = 16
+
+const defaultReorderWindow = 64
+
+// Pipeline is a source, some stages and a sink
+type Pipeline struct {
+	// Ordered keeps values in source order between stages
+	Ordered bool
+
+	// NewQueue makes the queue between each pair of stages.
+	// If nil, a linked.Queue with a capacity of 16 is used.
+	NewQueue func() queue.Queue
+
+	// ReorderWindow is the most results an ordered stage holds while
+	// waiting for an earlier one. Workers further ahead wait for room.
+	// If less than 1, 64 is used.
+	ReorderWindow int
+
+	source SourceFunc
+	stages []Stage
+	sink   SinkFunc
+}
+
+// New returns a new Pipeline running values from source through stages
+// into sink. The pipeline is unordered until Ordered is set.
+func New(source SourceFunc, sink SinkFunc, stages ...Stage) *Pipeline {
+	return &Pipeline{
+		source: source,
+		stages: stages,
+		sink:   sink,
+	}
+}
+
+// Run runs the pipeline until the source is drained, a stage fails or ctx
+// is done. It returns the first error seen, or nil.
+func (p *Pipeline) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var newQueue = p.NewQueue
+	if newQueue == nil {
+		newQueue = func() queue.Queue {
+			return linked.New(defaultQueueSize)
+		}
+	}
+
+	var window = p.ReorderWindow
+	if window < 1 {
+		window = defaultReorderWindow
+	}
+
+	// One pipe in front of each stage, and one in front of the sink.
+	// Each stage emits into the pipe after it
+	var pipes = make([]*pipe, len(p.stages)+1)
+	var emitters = make([]*emitter, len(p.stages))
+
+	for idx := range pipes {
+		pipes[idx] = newPipe(ctx, newQueue())
+	}
+
+	for idx := range emitters {
+		emitters[idx] = newEmitter(pipes[idx+1], p.Ordered, window)
+	}
+
+	// Wake everyone waiting on a pipe or for buffer room when we shut
+	// down, so they can see it
+	go func() {
+		<-ctx.Done()
+		for _, pp := range pipes {
+			pp.wake()
+		}
+
+		for _, e := range emitters {
+			e.wake()
+		}
+	}()
+
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+
+	// fail records the first error and stops everything
+	var fail = func(err error) {
+		if err == nil {
+			return
+		}
+
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	// Source
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer pipes[0].close()
+
+		// The source is a single goroutine, so it is always in order.
+		// The emitter numbers the items for us
+		var out = newEmitter(pipes[0], false, 0)
+
+		fail(p.source(ctx, func(value interface{}) error {
+			return out.emit(0, value, true)
+		}))
+	}()
+
+	// Stages. Each pipe is closed once every worker in front of it is done
+	for idx, stage := range p.stages {
+		var in, out = pipes[idx], emitters[idx]
+		var workers sync.WaitGroup
+
+		workers.Add(stage.workers)
+
+		for w := 0; w < stage.workers; w++ {
+			wg.Add(1)
+			go func(stage Stage) {
+				defer wg.Done()
+				defer workers.Done()
+
+				fail(stage.run(ctx, in, out))
+			}(stage)
+		}
+
+		wg.Add(1)
+		go func(next *pipe) {
+			defer wg.Done()
+			workers.Wait()
+			next.close()
+		}(pipes[idx+1])
+	}
+
+	// Sink
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		var in = pipes[len(pipes)-1]
+
+		for {
+			var it, ok, err = in.pop()
+			if err != nil || !ok {
+				fail(err)
+				return
+			}
+
+			if err = p.sink(ctx, it.value); err != nil {
+				fail(err)
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+
+	return firstErr
+}
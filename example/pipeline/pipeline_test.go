@@ -0,0 +1,289 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/noriah/go-code/structure/queue"
+	"github.com/noriah/go-code/structure/queue/channel"
+)
+
+func rangeSource(from, to int) SourceFunc {
+	return func(ctx context.Context, emit func(interface{}) error) error {
+		for num := from; num <= to; num++ {
+			if err := emit(num); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// jitter sleeps a little so workers finish out of order
+func jitter() {
+	time.Sleep(time.Duration(rand.Intn(200)) * time.Microsecond)
+}
+
+func collect(out *[]interface{}) SinkFunc {
+	return func(ctx context.Context, value interface{}) error {
+		*out = append(*out, value)
+		return nil
+	}
+}
+
+func TestPipelineOrdered(t *testing.T) {
+	var got []interface{}
+
+	p := New(rangeSource(1, 200), collect(&got),
+		Map(8, func(ctx context.Context, value interface{}) (interface{}, error) {
+			jitter()
+			return value.(int) * 2, nil
+		}),
+		Filter(4, func(ctx context.Context, value interface{}) (bool, error) {
+			jitter()
+			return value.(int)%3 != 0, nil
+		}),
+	)
+	p.Ordered = true
+	p.NewQueue = func() queue.Queue { return channel.New(4) }
+
+	if err := p.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var expect []interface{}
+	for num := 1; num <= 200; num++ {
+		if num*2%3 != 0 {
+			expect = append(expect, num*2)
+		}
+	}
+
+	if fmt.Sprint(got) != fmt.Sprint(expect) {
+		t.Errorf("expected %v, got %v", expect, got)
+	}
+}
+
+func TestPipelineUnordered(t *testing.T) {
+	var got []interface{}
+
+	p := New(rangeSource(1, 100), collect(&got),
+		Map(4, func(ctx context.Context, value interface{}) (interface{}, error) {
+			jitter()
+			return value, nil
+		}),
+	)
+
+	if err := p.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var nums = make([]int, len(got))
+	for idx, value := range got {
+		nums[idx] = value.(int)
+	}
+	sort.Ints(nums)
+
+	for idx, num := range nums {
+		if num != idx+1 {
+			t.Fatalf("expected %d, got %d", idx+1, num)
+		}
+	}
+
+	if len(nums) != 100 {
+		t.Errorf("expected %d values, got %d", 100, len(nums))
+	}
+}
+
+func TestPipelineBatch(t *testing.T) {
+	var got []interface{}
+
+	p := New(Values(1, 2, 3, 4, 5, 6, 7), collect(&got), Batch(3))
+	p.Ordered = true
+
+	if err := p.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if expect := "[[1 2 3] [4 5 6] [7]]"; fmt.Sprint(got) != expect {
+		t.Errorf("expected %s, got %v", expect, got)
+	}
+}
+
+func TestPipelineError(t *testing.T) {
+	var boom = errors.New("boom")
+
+	p := New(rangeSource(1, 1000000), func(ctx context.Context, value interface{}) error { return nil },
+		Map(4, func(ctx context.Context, value interface{}) (interface{}, error) {
+			if value.(int) == 50 {
+				return nil, boom
+			}
+			return value, nil
+		}),
+	)
+
+	if err := p.Run(context.Background()); err != boom {
+		t.Errorf("expected %v, got %v", boom, err)
+	}
+}
+
+func TestPipelineCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	p := New(rangeSource(1, 1000000), func(ctx context.Context, value interface{}) error {
+		if value.(int) == 10 {
+			cancel()
+		}
+		return nil
+	})
+
+	if err := p.Run(ctx); err != context.Canceled {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+}
+
+// startCounter returns a Map stage body that counts the values it starts,
+// blocking on value 1 until release is closed
+func startCounter(started *int32, release <-chan struct{}) MapFunc {
+	return func(ctx context.Context, value interface{}) (interface{}, error) {
+		atomic.AddInt32(started, 1)
+
+		if value.(int) == 1 {
+			select {
+			case <-release:
+			case <-ctx.Done():
+			}
+		}
+
+		return value, nil
+	}
+}
+
+func TestPipelineReorderWindow(t *testing.T) {
+	const workers, window = 8, 4
+
+	var got []interface{}
+	var started int32
+	var release = make(chan struct{})
+
+	p := New(rangeSource(1, 100), collect(&got), Map(workers, startCounter(&started, release)))
+	p.Ordered = true
+	p.ReorderWindow = window
+
+	var done = make(chan error)
+	go func() { done <- p.Run(context.Background()) }()
+
+	// Value 1 is stuck, so at most window results can be parked behind it,
+	// plus one more waiting for room in each other worker
+	time.Sleep(50 * time.Millisecond)
+
+	if n := atomic.LoadInt32(&started); n > window+workers {
+		t.Errorf("expected at most %d values started while the first is stuck, got %d", window+workers, n)
+	}
+
+	close(release)
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 100 || got[0] != 1 || got[99] != 100 {
+		t.Errorf("expected 1 to 100 in order, got %v", got)
+	}
+}
+
+func TestPipelineReorderCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var started int32
+
+	// Value 1 never finishes, so the other workers end up waiting for room
+	p := New(rangeSource(1, 100), collect(new([]interface{})), Map(4, startCounter(&started, nil)))
+	p.Ordered = true
+	p.ReorderWindow = 2
+
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	if err := p.Run(ctx); err != context.Canceled {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestPipelineSlowSink(t *testing.T) {
+	const workers, window = 4, 32
+
+	var started int32
+	var release = make(chan struct{})
+
+	// The sink sits on value 1, so the sender is stuck pushing
+	sink := func(ctx context.Context, value interface{}) error {
+		if value.(int) == 1 {
+			<-release
+		}
+		return nil
+	}
+
+	p := New(rangeSource(1, 200), sink,
+		Map(workers, func(ctx context.Context, value interface{}) (interface{}, error) {
+			atomic.AddInt32(&started, 1)
+			return value, nil
+		}),
+	)
+	p.Ordered = true
+	p.ReorderWindow = window
+	p.NewQueue = func() queue.Queue { return channel.New(1) }
+
+	var done = make(chan error)
+	go func() { done <- p.Run(context.Background()) }()
+
+	// The other workers keep parking results while the sender waits
+	for deadline := time.Now().Add(2 * time.Second); atomic.LoadInt32(&started) < window; {
+		if time.Now().After(deadline) {
+			t.Errorf("expected workers to fill the %d result window behind a slow sink, only %d started",
+				window, atomic.LoadInt32(&started))
+			break
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	close(release)
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func ExamplePipeline() {
+	p := New(rangeSource(1, 15),
+		func(ctx context.Context, value interface{}) error {
+			fmt.Println(value)
+			return nil
+		},
+		Map(3, func(ctx context.Context, value interface{}) (interface{}, error) {
+			switch num := value.(int); {
+			case num%15 == 0:
+				return "FizzBuzz", nil
+			case num%3 == 0:
+				return "Fizz", nil
+			case num%5 == 0:
+				return "Buzz", nil
+			default:
+				return num, nil
+			}
+		}),
+		Batch(5),
+	)
+	p.Ordered = true
+
+	p.Run(context.Background())
+	// Output:
+	// [1 2 Fizz 4 Buzz]
+	// [Fizz 7 8 Fizz Buzz]
+	// [11 Fizz 13 14 FizzBuzz]
+}
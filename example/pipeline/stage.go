@@ -0,0 +1,133 @@
+package pipeline
+
+import "context"
+
+// SourceFunc produces the values for a pipeline.
+// It calls emit once per value and returns when it has nothing left.
+// If emit returns an error, the pipeline is stopping and the source should
+// return that error.
+type SourceFunc func(ctx context.Context, emit func(value interface{}) error) error
+
+// MapFunc turns one value into another
+type MapFunc func(ctx context.Context, value interface{}) (interface{}, error)
+
+// FilterFunc reports whether a value should continue down the pipeline
+type FilterFunc func(ctx context.Context, value interface{}) (bool, error)
+
+// SinkFunc consumes the values at the end of a pipeline
+type SinkFunc func(ctx context.Context, value interface{}) error
+
+// Values returns a SourceFunc that emits each of values in turn
+func Values(values ...interface{}) SourceFunc {
+	return func(ctx context.Context, emit func(interface{}) error) error {
+		for _, value := range values {
+			if err := emit(value); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// Stage is a step between the source and the sink
+type Stage struct {
+	workers int        // Number of goroutines running this stage
+	mapFn   MapFunc    // Set for Map stages
+	filter  FilterFunc // Set for Filter stages
+	batch   int        // Set for Batch stages
+}
+
+// Map returns a stage that runs fn over every value using workers goroutines
+func Map(workers int, fn MapFunc) Stage {
+	return Stage{workers: checkWorkers(workers), mapFn: fn}
+}
+
+// Filter returns a stage that drops every value fn rejects, using workers
+// goroutines
+func Filter(workers int, fn FilterFunc) Stage {
+	return Stage{workers: checkWorkers(workers), filter: fn}
+}
+
+// Batch returns a stage that groups values into []interface{} of up to size.
+// The final batch may be short. Batch always runs on a single goroutine.
+func Batch(size int) Stage {
+	if size < 1 {
+		panic("Value less than 1 for batch size provided")
+	}
+
+	return Stage{workers: 1, batch: size}
+}
+
+// checkWorkers panics on a worker count that makes no sense
+func checkWorkers(workers int) int {
+	if workers < 1 {
+		panic("Value less than 1 for workers provided")
+	}
+
+	return workers
+}
+
+// run pulls from in and emits to out until in is drained or something fails
+func (s Stage) run(ctx context.Context, in *pipe, out *emitter) error {
+	if s.batch > 0 {
+		return s.runBatch(in, out)
+	}
+
+	for {
+		var it, ok, err = in.pop()
+		if err != nil || !ok {
+			return err
+		}
+
+		var value = it.value
+		var keep = true
+
+		if s.mapFn != nil {
+			value, err = s.mapFn(ctx, value)
+		} else {
+			keep, err = s.filter(ctx, value)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err = out.emit(it.seq, value, keep); err != nil {
+			return err
+		}
+	}
+}
+
+// runBatch collects values from in and emits them in groups
+func (s Stage) runBatch(in *pipe, out *emitter) error {
+	var batch = make([]interface{}, 0, s.batch)
+	var seq uint64
+
+	for {
+		var it, ok, err = in.pop()
+		if err != nil {
+			return err
+		}
+
+		// End of the stream. Send whatever we have left
+		if !ok {
+			if len(batch) > 0 {
+				return out.emit(seq, batch, true)
+			}
+
+			return nil
+		}
+
+		batch = append(batch, it.value)
+
+		if len(batch) == s.batch {
+			if err = out.emit(seq, batch, true); err != nil {
+				return err
+			}
+
+			seq++
+			batch = make([]interface{}, 0, s.batch)
+		}
+	}
+}
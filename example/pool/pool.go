@@ -0,0 +1,416 @@
+// Package pool implements a worker pool.
+// A pool runs a set of goroutines that pull tasks from a queue and run
+// them. The pool can be a fixed size, or grow up to a maximum when tasks
+// are waiting and shrink back when workers sit idle.
+//
+// Tasks that panic are recovered and reported as errors. Tasks with a
+// timeout get a context with a deadline. A task that ignores its context
+// keeps its worker busy until it returns, there is no way around that in Go.
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/noriah/go-code/structure/queue"
+	"github.com/noriah/go-code/structure/queue/linked"
+)
+
+// An error to be returned when Submit-ing to a pool that is shutting down
+var errorPoolClosed = errors.New("closed pool")
+
+// An error to be returned to waiters whose task was dropped by Stop
+var errorPoolStopped = errors.New("stopped pool")
+
+// Task is a unit of work.
+// ctx is cancelled if the pool is stopped or the task times out.
+type Task func(ctx context.Context) error
+
+// Config holds the settings for a Pool
+type Config struct {
+	// Workers is the number of goroutines always running. Must be at least 1.
+	Workers int
+
+	// MaxWorkers is the most goroutines the pool may grow to. If it is not
+	// more than Workers, the pool is a fixed size.
+	MaxWorkers int
+
+	// IdleTimeout is how long a worker over Workers waits for a task
+	// before exiting. Defaults to 1 second.
+	IdleTimeout time.Duration
+
+	// TaskTimeout is the default time limit for each task. 0 means no limit.
+	TaskTimeout time.Duration
+
+	// Queue holds submitted tasks. It must not be used by anything else.
+	// Defaults to an unbounded linked.Queue.
+	Queue queue.Queue
+}
+
+// Stats is a point in time view of a Pool
+type Stats struct {
+	Workers   int   // goroutines running
+	Idle      int   // goroutines waiting for a task
+	Queued    int   // tasks waiting for a worker
+	Running   int   // tasks being run
+	Completed int64 // tasks finished, whatever the result
+	Failed    int64 // tasks that returned an error
+	Panicked  int64 // tasks that panicked
+	TimedOut  int64 // tasks that ran past their timeout
+}
+
+// job is a task on the queue
+type job struct {
+	task    Task          // what to run
+	timeout time.Duration // time limit. 0 means none
+	done    chan error    // where to send the result. nil if nobody cares
+}
+
+// Pool is a set of goroutines running tasks from a queue
+type Pool struct {
+	mu     sync.Mutex         // Mutex for safe parallel operations
+	config Config             // Settings we were made with
+	queue  queue.Queue        // Tasks waiting to be run
+	wake   chan struct{}      // Nudges idle workers when a task arrives
+	closed chan struct{}      // Closed when we stop taking tasks
+	ctx    context.Context    // Parent of every task context
+	cancel context.CancelFunc // Cancels every running task
+	wg     sync.WaitGroup     // Waits for workers to exit
+	state  int                // running, closing or stopped
+	stats  Stats              // Our counts. Queued is filled on read
+}
+
+// Pool states
+const (
+	stateRunning = iota
+	stateClosing
+	stateStopped
+)
+
+const defaultIdleTimeout = time.Second
+
+// New returns a new Pool with its workers started
+func New(config Config) *Pool {
+	if config.Workers < 1 {
+		panic("Value less than 1 for workers provided")
+	}
+
+	if config.MaxWorkers < config.Workers {
+		config.MaxWorkers = config.Workers
+	}
+
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = defaultIdleTimeout
+	}
+
+	if config.Queue == nil {
+		config.Queue = linked.New()
+	}
+
+	var ctx, cancel = context.WithCancel(context.Background())
+
+	var p = &Pool{
+		config: config,
+		queue:  config.Queue,
+		wake:   make(chan struct{}, config.MaxWorkers),
+		closed: make(chan struct{}),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	p.mu.Lock()
+
+	for idx := 0; idx < config.Workers; idx++ {
+		p.spawn()
+	}
+
+	p.mu.Unlock()
+
+	return p
+}
+
+// Submit queues task to be run with the default timeout.
+// Returns error if the pool is shutting down or the queue is full.
+func (p *Pool) Submit(task Task) error {
+	return p.submit(&job{task: task, timeout: p.config.TaskTimeout})
+}
+
+// SubmitTimeout queues task to be run with its own timeout.
+// Returns error if the pool is shutting down or the queue is full.
+func (p *Pool) SubmitTimeout(task Task, timeout time.Duration) error {
+	return p.submit(&job{task: task, timeout: timeout})
+}
+
+// SubmitWait queues task and waits for it to finish, returning its error.
+// If ctx is done first, SubmitWait returns the context error. The task
+// still runs.
+func (p *Pool) SubmitWait(ctx context.Context, task Task) error {
+	var j = &job{
+		task:    task,
+		timeout: p.config.TaskTimeout,
+		done:    make(chan error, 1),
+	}
+
+	if err := p.submit(j); err != nil {
+		return err
+	}
+
+	select {
+	case err := <-j.done:
+		return err
+
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats returns the current state of the pool
+func (p *Pool) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var stats = p.stats
+	stats.Queued = p.queue.Size()
+
+	return stats
+}
+
+// Shutdown stops taking new tasks and waits for every queued and running
+// task to finish. If ctx is done first, Shutdown returns the context error
+// and the workers keep draining in the background.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.close(stateClosing)
+
+	var done = make(chan struct{})
+
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stop stops taking new tasks, drops everything still queued, cancels the
+// context of running tasks and waits for the workers to exit.
+// Anyone in SubmitWait on a dropped task gets an error.
+func (p *Pool) Stop() {
+	p.close(stateStopped)
+
+	p.cancel()
+
+	// Fail everything still waiting for a worker
+	p.mu.Lock()
+
+	for {
+		var value, err = p.queue.Pop()
+		if err != nil {
+			break
+		}
+
+		if j := value.(*job); j.done != nil {
+			j.done <- errorPoolStopped
+		}
+	}
+
+	p.mu.Unlock()
+
+	p.wg.Wait()
+}
+
+// Helper Methods
+// These methods are used internally.
+
+// submit puts j on the queue and makes sure someone will pick it up
+func (p *Pool) submit(j *job) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.state != stateRunning {
+		return errorPoolClosed
+	}
+
+	if err := p.queue.Push(j); err != nil {
+		return err
+	}
+
+	// Everyone is busy. Grow if we are allowed to
+	if p.stats.Idle == 0 && p.stats.Workers < p.config.MaxWorkers {
+		p.spawn()
+	}
+
+	// Nudge a waiting worker. If the channel is full, there are already
+	// enough nudges on the way
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// close moves the pool to state, if that is further along than where it is
+func (p *Pool) close(state int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if state <= p.state {
+		return
+	}
+
+	// First time out of running. Let the idle workers know
+	if p.state == stateRunning {
+		close(p.closed)
+	}
+
+	p.state = state
+}
+
+// spawn starts a new worker. The mutex must be held.
+func (p *Pool) spawn() {
+	p.stats.Workers++
+	p.wg.Add(1)
+
+	go p.work()
+}
+
+// work is the worker loop. It runs tasks until the pool says to stop, or
+// it has been idle too long and the pool has more workers than it needs.
+func (p *Pool) work() {
+	defer p.wg.Done()
+
+	var timer = time.NewTimer(p.config.IdleTimeout)
+	defer timer.Stop()
+
+	for {
+		var j, ok = p.next(timer)
+		if !ok {
+			return
+		}
+
+		p.run(j)
+	}
+}
+
+// next waits for a job. It returns false when the worker should exit.
+func (p *Pool) next(timer *time.Timer) (*job, bool) {
+	for {
+		p.mu.Lock()
+
+		if p.state == stateStopped {
+			p.stats.Workers--
+			p.mu.Unlock()
+			return nil, false
+		}
+
+		// Always check for work before waiting
+		if value, err := p.queue.Pop(); err == nil {
+			p.stats.Running++
+			p.mu.Unlock()
+			return value.(*job), true
+		}
+
+		// Nothing left and nothing more is coming
+		if p.state == stateClosing {
+			p.stats.Workers--
+			p.mu.Unlock()
+			return nil, false
+		}
+
+		p.stats.Idle++
+		p.mu.Unlock()
+
+		// Restart the idle clock
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(p.config.IdleTimeout)
+
+		var expired bool
+
+		select {
+		case <-p.wake:
+		case <-p.closed:
+		case <-timer.C:
+			expired = true
+		}
+
+		p.mu.Lock()
+
+		p.stats.Idle--
+
+		// Idle too long and we have more workers than we need
+		if expired && p.stats.Workers > p.config.Workers && p.queue.IsEmpty() {
+			p.stats.Workers--
+			p.mu.Unlock()
+			return nil, false
+		}
+
+		p.mu.Unlock()
+	}
+}
+
+// run runs j, catching panics and enforcing its timeout
+func (p *Pool) run(j *job) {
+	var ctx, cancel = p.ctx, context.CancelFunc(func() {})
+	if j.timeout > 0 {
+		ctx, cancel = context.WithTimeout(p.ctx, j.timeout)
+	}
+
+	var panicked, err = call(ctx, j.task)
+	var timedOut = j.timeout > 0 && ctx.Err() == context.DeadlineExceeded
+
+	cancel()
+
+	// A task that ran over its time fails with the deadline, even if it
+	// finished in the end
+	if timedOut && err == nil {
+		err = context.DeadlineExceeded
+	}
+
+	p.mu.Lock()
+
+	p.stats.Running--
+	p.stats.Completed++
+
+	if err != nil {
+		p.stats.Failed++
+	}
+
+	if panicked {
+		p.stats.Panicked++
+	}
+
+	if timedOut {
+		p.stats.TimedOut++
+	}
+
+	p.mu.Unlock()
+
+	if j.done != nil {
+		j.done <- err
+	}
+}
+
+// call runs task, turning a panic into an error
+func call(ctx context.Context, task Task) (panicked bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicked, err = true, fmt.Errorf("task panicked: %v", r)
+		}
+	}()
+
+	return false, task(ctx)
+}
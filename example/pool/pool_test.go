@@ -0,0 +1,175 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/noriah/go-code/structure/queue/channel"
+)
+
+func TestPoolSubmit(t *testing.T) {
+	pool := New(Config{Workers: 4})
+
+	var count int64
+	for i := 0; i < 100; i++ {
+		if err := pool.Submit(func(ctx context.Context) error {
+			atomic.AddInt64(&count, 1)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 100 {
+		t.Errorf("expected %d tasks run, got %d", 100, count)
+	}
+
+	stats := pool.Stats()
+	if stats.Completed != 100 || stats.Workers != 0 || stats.Queued != 0 {
+		t.Errorf("unexpected stats after shutdown: %+v", stats)
+	}
+
+	if err := pool.Submit(func(ctx context.Context) error { return nil }); err != errorPoolClosed {
+		t.Errorf("expected %v, got %v", errorPoolClosed, err)
+	}
+}
+
+func TestPoolSubmitWait(t *testing.T) {
+	pool := New(Config{Workers: 2})
+	defer pool.Stop()
+
+	boom := errors.New("boom")
+
+	if err := pool.SubmitWait(context.Background(), func(ctx context.Context) error {
+		return boom
+	}); err != boom {
+		t.Errorf("expected %v, got %v", boom, err)
+	}
+
+	err := pool.SubmitWait(context.Background(), func(ctx context.Context) error {
+		panic("oops")
+	})
+	if err == nil {
+		t.Error("expected error from panicking task")
+	}
+
+	stats := pool.Stats()
+	if stats.Failed != 2 || stats.Panicked != 1 {
+		t.Errorf("expected 2 failed and 1 panicked, got %+v", stats)
+	}
+}
+
+func TestPoolTimeout(t *testing.T) {
+	pool := New(Config{Workers: 1, TaskTimeout: 10 * time.Millisecond})
+	defer pool.Stop()
+
+	err := pool.SubmitWait(context.Background(), func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	if err != context.DeadlineExceeded {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	if stats := pool.Stats(); stats.TimedOut != 1 {
+		t.Errorf("expected %d timed out, got %d", 1, stats.TimedOut)
+	}
+}
+
+func TestPoolStop(t *testing.T) {
+	pool := New(Config{Workers: 1})
+
+	started := make(chan struct{})
+	pool.Submit(func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	<-started
+
+	waited := make(chan error, 1)
+	go func() {
+		waited <- pool.SubmitWait(context.Background(), func(ctx context.Context) error {
+			return nil
+		})
+	}()
+
+	// Make sure the second task is queued before we stop
+	for pool.Stats().Queued == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	pool.Stop()
+
+	if err := <-waited; err != errorPoolStopped {
+		t.Errorf("expected %v, got %v", errorPoolStopped, err)
+	}
+}
+
+func TestPoolAutoscale(t *testing.T) {
+	pool := New(Config{Workers: 1, MaxWorkers: 4, IdleTimeout: 10 * time.Millisecond})
+	defer pool.Stop()
+
+	release := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		pool.Submit(func(ctx context.Context) error {
+			<-release
+			return nil
+		})
+	}
+
+	for pool.Stats().Running != 4 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if workers := pool.Stats().Workers; workers != 4 {
+		t.Errorf("expected %d workers, got %d", 4, workers)
+	}
+
+	close(release)
+
+	// Extra workers go away once idle
+	deadline := time.Now().Add(time.Second)
+	for pool.Stats().Workers != 1 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if workers := pool.Stats().Workers; workers != 1 {
+		t.Errorf("expected pool to shrink to %d workers, got %d", 1, workers)
+	}
+}
+
+func TestPoolBoundedQueue(t *testing.T) {
+	pool := New(Config{Workers: 1, Queue: channel.New(1)})
+	defer pool.Stop()
+
+	release := make(chan struct{})
+	block := func(ctx context.Context) error {
+		<-release
+		return nil
+	}
+
+	pool.Submit(block)
+
+	for pool.Stats().Running != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := pool.Submit(block); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pool.Submit(block); err == nil {
+		t.Error("expected full queue error")
+	}
+
+	close(release)
+}
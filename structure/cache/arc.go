@@ -0,0 +1,314 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/noriah/go-code/structure/list"
+)
+
+// arcList is one of the four ARC lists, and the total cost in it
+type arcList struct {
+	*list.List
+	cost  int  // Total cost of the entries in the list
+	ghost bool // Entries here only remember a key that was evicted
+}
+
+// arcEntry is an entry and the list it sits in
+type arcEntry struct {
+	entry
+	in *arcList // List the entry is in
+}
+
+// ARC implements an Adaptive Replacement Cache (Megiddo and Modha, 2003).
+// Entries seen once live in t1, and entries seen again move to t2. When an
+// entry is evicted its key is remembered in a ghost list, b1 or b2, to
+// match the list it left. A miss that hits a ghost says that list was cut
+// too short, so the target size p for t1 moves toward it.
+//
+// The cache balances itself between recency and frequency, and a one-off
+// scan of new keys only churns t1 without flushing t2.
+//
+// Sizes here are measured in cost, so with the default cost of 1 this is
+// ARC as published.
+type ARC struct {
+	base
+	items map[interface{}]*list.Element // Elements in their list, by key. Ghosts too
+	t1    *arcList                      // Seen once recently. Most recent at the front
+	t2    *arcList                      // Seen at least twice recently
+	b1    *arcList                      // Ghosts evicted from t1
+	b2    *arcList                      // Ghosts evicted from t2
+	p     int                           // Target cost for t1
+}
+
+// NewARC returns a new ARC cache
+func NewARC(config Config) *ARC {
+	var c = &ARC{base: newBase(config)}
+	c.Clear()
+
+	return c
+}
+
+// Get returns the value for key, and marks it as seen again.
+//
+// Time: O(1)
+func (c *ARC) Get(key interface{}) (interface{}, error) {
+	var el, ok = c.items[key]
+	if !ok {
+		return nil, errorKeyNotFound
+	}
+
+	var e = el.Value.(*arcEntry)
+
+	if e.in.ghost {
+		return nil, errorKeyNotFound
+	}
+
+	if c.expired(&e.entry) {
+		c.remove(el, true)
+		return nil, errorKeyNotFound
+	}
+
+	c.move(el, c.t2)
+
+	return e.value, nil
+}
+
+// Has returns true if key is in the cache
+func (c *ARC) Has(key interface{}) bool {
+	var el, ok = c.items[key]
+	if !ok {
+		return false
+	}
+
+	var e = el.Value.(*arcEntry)
+
+	return !e.in.ghost && !c.expired(&e.entry)
+}
+
+// Put sets the value for key, using the default TTL
+//
+// Time: O(1), plus O(1) per entry evicted
+func (c *ARC) Put(key, value interface{}) error {
+	return c.PutTTL(key, value, c.config.TTL)
+}
+
+// PutTTL sets the value for key, expiring after ttl. Replacing a value
+// counts as seeing it again. Returns error if the entry costs more than
+// the whole capacity.
+//
+// Time: O(1), plus O(1) per entry evicted
+func (c *ARC) PutTTL(key, value interface{}, ttl time.Duration) error {
+	var el, ok = c.items[key]
+
+	if !ok {
+		var e = &arcEntry{entry: entry{key: key}}
+		if err := c.fill(&e.entry, value, ttl); err != nil {
+			return err
+		}
+
+		c.replace(e.cost, false)
+
+		e.in = c.t1
+		c.t1.cost += e.cost
+		c.items[key] = c.t1.PushFront(e)
+
+		c.trim()
+
+		return nil
+	}
+
+	var e = el.Value.(*arcEntry)
+	var from, old = e.in, e.cost
+
+	// A ghost's cost is not part of the total any more
+	if from.ghost {
+		e.cost = 0
+	}
+
+	if err := c.fill(&e.entry, value, ttl); err != nil {
+		e.cost = old
+		return err
+	}
+
+	// A ghost hit. The list it was in was too short, so grow its target
+	switch from {
+	case c.b1:
+		c.p = min(c.p+e.cost*max(c.b2.cost/max(c.b1.cost, 1), 1), c.config.Capacity)
+	case c.b2:
+		c.p = max(c.p-e.cost*max(c.b1.cost/max(c.b2.cost, 1), 1), 0)
+	}
+
+	// Take it out while we make room, so it cannot evict itself
+	from.Remove(el)
+	from.cost -= old
+
+	c.replace(e.cost, from == c.b2)
+
+	e.in = c.t2
+	c.t2.cost += e.cost
+	c.items[key] = c.t2.PushFront(e)
+
+	c.trim()
+
+	return nil
+}
+
+// Delete removes key from the cache.
+// Returns error if the key is not in the cache.
+func (c *ARC) Delete(key interface{}) error {
+	var el, ok = c.items[key]
+	if !ok {
+		return errorKeyNotFound
+	}
+
+	if el.Value.(*arcEntry).in.ghost {
+		c.forget(el)
+		return errorKeyNotFound
+	}
+
+	c.remove(el, false)
+
+	return nil
+}
+
+// Purge removes every expired entry and returns how many there were
+//
+// Time: O(n)
+func (c *ARC) Purge() int {
+	var count int
+
+	for _, l := range []*arcList{c.t1, c.t2} {
+		l.Forward(func(el *list.Element) bool {
+			if c.expired(&el.Value.(*arcEntry).entry) {
+				c.remove(el, true)
+				count++
+			}
+
+			return true
+		})
+	}
+
+	return count
+}
+
+// Size returns the number of entries in the cache
+func (c *ARC) Size() int {
+	return c.t1.Size() + c.t2.Size()
+}
+
+// Clear empties the cache, and forgets every ghost
+func (c *ARC) Clear() {
+	c.items = make(map[interface{}]*list.Element)
+	c.t1 = &arcList{List: list.New()}
+	c.t2 = &arcList{List: list.New()}
+	c.b1 = &arcList{List: list.New(), ghost: true}
+	c.b2 = &arcList{List: list.New(), ghost: true}
+	c.p = 0
+	c.cost = 0
+}
+
+// Helper Methods
+// These methods are used internally.
+
+// replace evicts from t1 or t2 until cost more fits. t1 gives up entries
+// while it is over its target p, t2 otherwise. inB2 breaks the tie at
+// exactly p in t2's favour, as the paper does.
+func (c *ARC) replace(cost int, inB2 bool) {
+	for c.t1.cost+c.t2.cost+cost > c.config.Capacity {
+		var from, to = c.t2, c.b2
+
+		if c.t1.Size() > 0 && (c.t1.cost > c.p || inB2 && c.t1.cost == c.p || c.t2.Size() == 0) {
+			from, to = c.t1, c.b1
+		}
+
+		var el = from.Back()
+		var e = el.Value.(*arcEntry)
+
+		from.Remove(el)
+		from.cost -= e.cost
+		c.dropped(&e.entry, true)
+
+		// Keep the key and cost, so a hit can tell how much room it wanted
+		e.value = nil
+		e.in = to
+		to.cost += e.cost
+		c.items[e.key] = to.PushFront(e)
+	}
+}
+
+// trim forgets the oldest ghosts, keeping t1 and b1 within the capacity,
+// and all four lists within twice that
+func (c *ARC) trim() {
+	var capacity = c.config.Capacity
+
+	for c.t1.cost+c.b1.cost > capacity && c.b1.Size() > 0 {
+		c.forget(c.b1.Back())
+	}
+
+	for c.t1.cost+c.t2.cost+c.b1.cost+c.b2.cost > 2*capacity {
+		var from = c.b2
+		if from.Size() == 0 {
+			from = c.b1
+		}
+
+		if from.Size() == 0 {
+			return
+		}
+
+		c.forget(from.Back())
+	}
+}
+
+// move puts el at the front of to
+func (c *ARC) move(el *list.Element, to *arcList) {
+	var e = el.Value.(*arcEntry)
+
+	if e.in == to {
+		to.MoveToFront(el)
+		return
+	}
+
+	e.in.Remove(el)
+	e.in.cost -= e.cost
+
+	e.in = to
+	to.cost += e.cost
+	c.items[e.key] = to.PushFront(e)
+}
+
+// forget drops a ghost
+func (c *ARC) forget(el *list.Element) {
+	var e = el.Value.(*arcEntry)
+
+	e.in.Remove(el)
+	e.in.cost -= e.cost
+	delete(c.items, e.key)
+}
+
+// remove drops a live entry from the cache, without leaving a ghost
+func (c *ARC) remove(el *list.Element, evicted bool) {
+	var e = el.Value.(*arcEntry)
+
+	e.in.Remove(el)
+	e.in.cost -= e.cost
+	delete(c.items, e.key)
+	c.dropped(&e.entry, evicted)
+}
+
+// min returns the smaller of a and b
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+// max returns the bigger of a and b
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
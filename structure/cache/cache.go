@@ -0,0 +1,191 @@
+// Package cache holds implementations for bounded key/value caches.
+// A cache holds as much as its capacity allows, and when something new
+// needs room it evicts whatever its policy says is least worth keeping.
+//
+// The policies themselves are not safe for parallel use, so they can be
+// composed and tested without locking. Wrap one in a Safe to share it
+// between goroutines.
+package cache
+
+import (
+	"errors"
+	"time"
+)
+
+// An error to be returned when a key is not in the cache, or has expired
+var errorKeyNotFound = errors.New("key not found")
+
+// An error to be returned when Put-ing an entry that costs more than
+// the whole capacity
+var errorTooLarge = errors.New("entry larger than capacity")
+
+// Cache is the surface every policy shares
+type Cache interface {
+	// Get returns the value for key, and counts as a use of it
+	Get(key interface{}) (interface{}, error)
+
+	// Has returns true if key is in the cache. It does not count as a use
+	Has(key interface{}) bool
+
+	// Put sets the value for key, using the default TTL
+	Put(key, value interface{}) error
+
+	// PutTTL sets the value for key. It expires after ttl, or never if
+	// ttl is 0 or less
+	PutTTL(key, value interface{}, ttl time.Duration) error
+
+	// Delete removes key from the cache, without calling OnEvict
+	Delete(key interface{}) error
+
+	// Purge removes every expired entry and returns how many there were
+	Purge() int
+
+	// Size returns the number of entries in the cache
+	Size() int
+
+	// Cost returns the total cost of the entries in the cache
+	Cost() int
+
+	// Clear empties the cache, without calling OnEvict
+	Clear()
+}
+
+// Reason says why an entry left the cache
+type Reason int
+
+const (
+	// Evicted entries were pushed out to make room
+	Evicted Reason = iota
+
+	// Expired entries outlived their TTL
+	Expired
+)
+
+// String returns the name of the reason
+func (r Reason) String() string {
+	switch r {
+	case Evicted:
+		return "evicted"
+	case Expired:
+		return "expired"
+	default:
+		return "unknown"
+	}
+}
+
+// Config holds the settings for a cache
+type Config struct {
+	// Capacity is the most entries the cache holds, or the most total cost
+	// if Cost is set. Must be at least 1.
+	Capacity int
+
+	// Cost returns what an entry counts against Capacity. Must not be
+	// negative. nil counts every entry as 1.
+	Cost func(key, value interface{}) int
+
+	// TTL is how long entries added with Put live. 0 means forever.
+	TTL time.Duration
+
+	// OnEvict is called with every entry that is evicted or found expired.
+	// It is not called for Delete, Clear, or a Put that replaces a value.
+	OnEvict func(key, value interface{}, reason Reason)
+
+	// Now tells the time for TTLs. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// entry is a key and its value in a cache
+type entry struct {
+	key     interface{} // Key the entry is stored under
+	value   interface{} // Value held for the key
+	cost    int         // What the entry counts against capacity
+	expires time.Time   // When the entry expires. Zero means never
+}
+
+// base is the bookkeeping every policy shares
+type base struct {
+	config Config // Settings we were made with
+	cost   int    // Total cost of the entries held
+}
+
+// Cost returns the total cost of the entries in the cache
+func (b *base) Cost() int {
+	return b.cost
+}
+
+// Helper Methods
+// These methods are used internally.
+
+// newBase checks config and fills in its defaults
+func newBase(config Config) base {
+	if config.Capacity < 1 {
+		panic("Value less than 1 for capacity provided")
+	}
+
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+
+	return base{config: config}
+}
+
+// costOf returns what key and value count against capacity
+func (b *base) costOf(key, value interface{}) int {
+	if b.config.Cost == nil {
+		return 1
+	}
+
+	var cost = b.config.Cost(key, value)
+	if cost < 0 {
+		panic("Negative cost returned")
+	}
+
+	return cost
+}
+
+// fill sets e's value, cost and expiry. Returns error if it can never fit
+func (b *base) fill(e *entry, value interface{}, ttl time.Duration) error {
+	var cost = b.costOf(e.key, value)
+	if cost > b.config.Capacity {
+		return errorTooLarge
+	}
+
+	b.cost += cost - e.cost
+
+	e.value = value
+	e.cost = cost
+	e.expires = time.Time{}
+
+	if ttl > 0 {
+		e.expires = b.config.Now().Add(ttl)
+	}
+
+	return nil
+}
+
+// full returns true if the cache is over capacity
+func (b *base) full() bool {
+	return b.cost > b.config.Capacity
+}
+
+// expired returns true if e has outlived its TTL
+func (b *base) expired(e *entry) bool {
+	return !e.expires.IsZero() && !b.config.Now().Before(e.expires)
+}
+
+// dropped takes e's cost off the total and tells OnEvict, if it was not
+// just deleted
+func (b *base) dropped(e *entry, evicted bool) {
+	b.cost -= e.cost
+
+	if !evicted || b.config.OnEvict == nil {
+		return
+	}
+
+	var reason = Evicted
+	if b.expired(e) {
+		reason = Expired
+	}
+
+	b.config.OnEvict(e.key, e.value, reason)
+}
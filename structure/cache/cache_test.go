@@ -0,0 +1,420 @@
+package cache
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	_ Cache = (*LRU)(nil)
+	_ Cache = (*LFU)(nil)
+	_ Cache = (*ARC)(nil)
+	_ Cache = (*Safe)(nil)
+)
+
+var policies = []struct {
+	name string
+	new  func(config Config) Cache
+}{
+	{"LRU", func(config Config) Cache { return NewLRU(config) }},
+	{"LFU", func(config Config) Cache { return NewLFU(config) }},
+	{"ARC", func(config Config) Cache { return NewARC(config) }},
+}
+
+// fakeClock is a clock that only moves when told to
+type fakeClock struct {
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	return f.now
+}
+
+// checkCache fails the test if the bookkeeping inside c is off
+func checkCache(t *testing.T, c Cache) {
+	t.Helper()
+
+	var cost, size int
+
+	switch c := c.(type) {
+	case *LRU:
+		for el := c.order.Front(); el != nil; el = el.Next() {
+			cost += el.Value.(*entry).cost
+			size++
+		}
+
+		if size != len(c.items) {
+			t.Fatalf("LRU: %d in the list, %d in the map", size, len(c.items))
+		}
+
+	case *LFU:
+		var last int
+		for b := c.buckets.Front(); b != nil; b = b.Next() {
+			var bk = b.Value.(*bucket)
+			if bk.uses <= last || bk.entries.IsEmpty() {
+				t.Fatalf("LFU: bucket for %d uses after %d, %d entries", bk.uses, last, bk.entries.Size())
+			}
+
+			last = bk.uses
+
+			for el := bk.entries.Front(); el != nil; el = el.Next() {
+				if el.Value.(*lfuEntry).bucket != b {
+					t.Fatalf("LFU: entry points at the wrong bucket")
+				}
+
+				cost += el.Value.(*lfuEntry).cost
+				size++
+			}
+		}
+
+		if size != len(c.items) {
+			t.Fatalf("LFU: %d in buckets, %d in the map", size, len(c.items))
+		}
+
+	case *ARC:
+		var all int
+		for _, l := range []*arcList{c.t1, c.t2, c.b1, c.b2} {
+			var listCost int
+			for el := l.Front(); el != nil; el = el.Next() {
+				if el.Value.(*arcEntry).in != l {
+					t.Fatalf("ARC: entry points at the wrong list")
+				}
+
+				listCost += el.Value.(*arcEntry).cost
+				all++
+			}
+
+			if listCost != l.cost {
+				t.Fatalf("ARC: list holds cost %d, recorded %d", listCost, l.cost)
+			}
+		}
+
+		cost, size = c.t1.cost+c.t2.cost, c.t1.Size()+c.t2.Size()
+		capacity := c.config.Capacity
+
+		if all != len(c.items) {
+			t.Fatalf("ARC: %d in lists, %d in the map", all, len(c.items))
+		}
+
+		if c.t1.cost+c.b1.cost > capacity || cost+c.b1.cost+c.b2.cost > 2*capacity {
+			t.Fatalf("ARC: lists too long, t1 %d t2 %d b1 %d b2 %d", c.t1.cost, c.t2.cost, c.b1.cost, c.b2.cost)
+		}
+
+		if c.p < 0 || c.p > capacity {
+			t.Fatalf("ARC: target %d out of range", c.p)
+		}
+	}
+
+	if cost != c.Cost() || size != c.Size() {
+		t.Fatalf("expected size %d and cost %d, got %d and %d", size, cost, c.Size(), c.Cost())
+	}
+}
+
+func TestBasics(t *testing.T) {
+	for _, policy := range policies {
+		c := policy.new(Config{Capacity: 3})
+
+		if _, err := c.Get("a"); err != errorKeyNotFound {
+			t.Errorf("%s: expected %v, got %v", policy.name, errorKeyNotFound, err)
+		}
+
+		c.Put("a", 1)
+		c.Put("b", 2)
+		c.Put("a", 10)
+		checkCache(t, c)
+
+		if value, err := c.Get("a"); err != nil || value != 10 {
+			t.Errorf("%s: Get(a): expected 10, got %v (%v)", policy.name, value, err)
+		}
+
+		if c.Size() != 2 || !c.Has("b") || c.Has("z") {
+			t.Errorf("%s: expected a and b only, got size %d", policy.name, c.Size())
+		}
+
+		if err := c.Delete("b"); err != nil || c.Has("b") {
+			t.Errorf("%s: Delete(b): %v", policy.name, err)
+		}
+
+		if err := c.Delete("b"); err != errorKeyNotFound {
+			t.Errorf("%s: Delete twice: expected %v, got %v", policy.name, errorKeyNotFound, err)
+		}
+
+		for i := 0; i < 10; i++ {
+			c.Put(i, i)
+			checkCache(t, c)
+		}
+
+		if c.Size() != 3 {
+			t.Errorf("%s: expected size %d, got %d", policy.name, 3, c.Size())
+		}
+
+		c.Clear()
+		checkCache(t, c)
+
+		if c.Size() != 0 || c.Cost() != 0 {
+			t.Errorf("%s: expected empty cache after Clear", policy.name)
+		}
+	}
+}
+
+func TestTTL(t *testing.T) {
+	for _, policy := range policies {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		var expired []interface{}
+
+		c := policy.new(Config{
+			Capacity: 10,
+			TTL:      time.Minute,
+			Now:      clock.Now,
+			OnEvict: func(key, value interface{}, reason Reason) {
+				if reason != Expired {
+					t.Errorf("%s: %v left for %s, expected expired", policy.name, key, reason)
+				}
+
+				expired = append(expired, key)
+			},
+		})
+
+		c.Put("default", nil)
+		c.PutTTL("short", nil, time.Second)
+		c.PutTTL("forever", nil, 0)
+		c.PutTTL("hour", nil, time.Hour)
+
+		clock.now = clock.now.Add(time.Second)
+
+		if c.Has("short") {
+			t.Errorf("%s: short should have expired", policy.name)
+		}
+
+		if _, err := c.Get("short"); err != errorKeyNotFound {
+			t.Errorf("%s: Get(short): expected %v, got %v", policy.name, errorKeyNotFound, err)
+		}
+
+		clock.now = clock.now.Add(time.Minute)
+
+		if n := c.Purge(); n != 1 {
+			t.Errorf("%s: Purge: expected 1 expired, got %d", policy.name, n)
+		}
+
+		checkCache(t, c)
+
+		if fmt.Sprint(expired) != "[short default]" {
+			t.Errorf("%s: expected [short default] to expire, got %v", policy.name, expired)
+		}
+
+		if !c.Has("forever") || !c.Has("hour") || c.Size() != 2 {
+			t.Errorf("%s: expected forever and hour to be left, got size %d", policy.name, c.Size())
+		}
+	}
+}
+
+func TestCost(t *testing.T) {
+	for _, policy := range policies {
+		var evicted []interface{}
+
+		c := policy.new(Config{
+			Capacity: 10,
+			Cost:     func(key, value interface{}) int { return len(value.(string)) },
+			OnEvict: func(key, value interface{}, reason Reason) {
+				if reason != Evicted {
+					t.Errorf("%s: %v left for %s, expected evicted", policy.name, key, reason)
+				}
+
+				evicted = append(evicted, key)
+			},
+		})
+
+		c.Put("a", "aaaa")
+		c.Put("b", "bbbb")
+		checkCache(t, c)
+
+		if c.Cost() != 8 {
+			t.Errorf("%s: expected cost 8, got %d", policy.name, c.Cost())
+		}
+
+		if err := c.Put("big", "0123456789a"); err != errorTooLarge {
+			t.Errorf("%s: expected %v, got %v", policy.name, errorTooLarge, err)
+		}
+
+		// Growing b pushes a out
+		c.Put("b", "bbbbbbb")
+		checkCache(t, c)
+
+		if c.Has("a") || !c.Has("b") || c.Cost() != 7 {
+			t.Errorf("%s: expected only b at cost 7, got cost %d", policy.name, c.Cost())
+		}
+
+		// Too large leaves the old value alone
+		if err := c.Put("b", "0123456789a"); err != errorTooLarge {
+			t.Errorf("%s: expected %v, got %v", policy.name, errorTooLarge, err)
+		}
+
+		if value, _ := c.Get("b"); value != "bbbbbbb" {
+			t.Errorf("%s: expected b to keep its value, got %v", policy.name, value)
+		}
+
+		checkCache(t, c)
+
+		if fmt.Sprint(evicted) != "[a]" {
+			t.Errorf("%s: expected [a] evicted, got %v", policy.name, evicted)
+		}
+	}
+}
+
+func TestEvictionOrder(t *testing.T) {
+	var evicted interface{}
+	config := Config{
+		Capacity: 3,
+		OnEvict:  func(key, value interface{}, reason Reason) { evicted = key },
+	}
+
+	tests := []struct {
+		name   string
+		cache  Cache
+		expect string
+	}{
+		// a is used most, but least recently
+		{"LRU", NewLRU(config), "a"},
+
+		// b and c are used least, and b less recently
+		{"LFU", NewLFU(config), "b"},
+	}
+
+	for _, test := range tests {
+		c := test.cache
+
+		c.Put("a", nil)
+		c.Put("b", nil)
+		c.Put("c", nil)
+		c.Get("a")
+		c.Get("a")
+		c.Get("b")
+		c.Get("c")
+
+		c.Put("d", nil)
+		checkCache(t, c)
+
+		if evicted != test.expect {
+			t.Errorf("%s: expected %s evicted, got %v", test.name, test.expect, evicted)
+		}
+	}
+}
+
+func TestScanResistance(t *testing.T) {
+	const capacity = 100
+
+	// A hot set used over and over, then one long scan of keys never
+	// seen again. ARC should keep the hot set, LRU loses it
+	survivors := map[string]int{}
+
+	for _, policy := range policies {
+		c := policy.new(Config{Capacity: capacity})
+
+		for round := 0; round < 5; round++ {
+			for key := 0; key < capacity/2; key++ {
+				if _, err := c.Get(key); err != nil {
+					c.Put(key, nil)
+				}
+			}
+		}
+
+		for key := capacity; key < 20*capacity; key++ {
+			if _, err := c.Get(key); err != nil {
+				c.Put(key, nil)
+			}
+		}
+
+		checkCache(t, c)
+
+		for key := 0; key < capacity/2; key++ {
+			if c.Has(key) {
+				survivors[policy.name]++
+			}
+		}
+	}
+
+	if survivors["LRU"] != 0 {
+		t.Errorf("LRU: expected the scan to flush the hot set, %d survived", survivors["LRU"])
+	}
+
+	if survivors["ARC"] < capacity/4 {
+		t.Errorf("ARC: expected most of the hot set to survive, %d of %d did", survivors["ARC"], capacity/2)
+	}
+}
+
+func TestRandomized(t *testing.T) {
+	for _, policy := range policies {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		rng := rand.New(rand.NewSource(1))
+
+		c := policy.new(Config{
+			Capacity: 50,
+			Cost:     func(key, value interface{}) int { return value.(int) % 5 },
+			Now:      clock.Now,
+		})
+
+		last := map[int]int{}
+
+		for i := 0; i < 20000; i++ {
+			key := rng.Intn(200)
+
+			switch rng.Intn(10) {
+			case 0:
+				c.Delete(key)
+			case 1:
+				clock.now = clock.now.Add(time.Second)
+			case 2:
+				c.Purge()
+			case 3, 4, 5:
+				c.PutTTL(key, i, time.Duration(rng.Intn(20))*time.Second)
+				last[key] = i
+			default:
+				if value, err := c.Get(key); err == nil && value != last[key] {
+					t.Fatalf("%s: Get(%d): expected %d, got %v", policy.name, key, last[key], value)
+				}
+			}
+
+			checkCache(t, c)
+		}
+	}
+}
+
+func TestSafe(t *testing.T) {
+	for _, policy := range policies {
+		c := NewSafe(policy.new(Config{Capacity: 64}))
+
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+
+				rng := rand.New(rand.NewSource(int64(g)))
+				for i := 0; i < 2000; i++ {
+					key := rng.Intn(128)
+
+					switch rng.Intn(4) {
+					case 0:
+						c.Put(key, key)
+					case 1:
+						c.Delete(key)
+					default:
+						if value, err := c.Get(key); err == nil && value != key {
+							t.Errorf("%s: Get(%d): got %v", policy.name, key, value)
+						}
+					}
+				}
+			}(g)
+		}
+
+		wg.Wait()
+		checkCache(t, c.cache)
+
+		if c.Size() > 64 {
+			t.Errorf("%s: expected at most %d entries, got %d", policy.name, 64, c.Size())
+		}
+	}
+}
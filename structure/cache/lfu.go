@@ -0,0 +1,225 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/noriah/go-code/structure/list"
+)
+
+// bucket holds every entry used the same number of times
+type bucket struct {
+	uses    int        // How many times each entry here has been used
+	entries *list.List // *lfuEntry, most recently used at the front
+}
+
+// lfuEntry is an entry and where it sits
+type lfuEntry struct {
+	entry
+	bucket *list.Element // Our *bucket in the bucket list
+}
+
+// LFU implements a Least Frequently Used cache.
+// Entries are grouped into buckets by how many times they have been used,
+// and the buckets are kept in a list sorted by that count. The entry to
+// evict is always in the first bucket, and a use only moves an entry to
+// the bucket next door, so every operation is O(1). Ties go to the entry
+// used least recently.
+type LFU struct {
+	base
+	items   map[interface{}]*list.Element // Elements in their bucket, by key
+	buckets *list.List                    // *bucket, fewest uses at the front
+}
+
+// NewLFU returns a new LFU cache
+func NewLFU(config Config) *LFU {
+	return &LFU{
+		base:    newBase(config),
+		items:   make(map[interface{}]*list.Element),
+		buckets: list.New(),
+	}
+}
+
+// Get returns the value for key, and counts a use of it.
+//
+// Time: O(1)
+func (c *LFU) Get(key interface{}) (interface{}, error) {
+	var el, ok = c.items[key]
+	if !ok {
+		return nil, errorKeyNotFound
+	}
+
+	var e = el.Value.(*lfuEntry)
+
+	if c.expired(&e.entry) {
+		c.remove(el, true)
+		return nil, errorKeyNotFound
+	}
+
+	c.use(el)
+
+	return e.value, nil
+}
+
+// Has returns true if key is in the cache
+func (c *LFU) Has(key interface{}) bool {
+	var el, ok = c.items[key]
+
+	return ok && !c.expired(&el.Value.(*lfuEntry).entry)
+}
+
+// Put sets the value for key, using the default TTL
+//
+// Time: O(1), plus O(1) per entry evicted
+func (c *LFU) Put(key, value interface{}) error {
+	return c.PutTTL(key, value, c.config.TTL)
+}
+
+// PutTTL sets the value for key, expiring after ttl. Replacing a value
+// counts as a use. Returns error if the entry costs more than the whole
+// capacity.
+//
+// Time: O(1), plus O(1) per entry evicted
+func (c *LFU) PutTTL(key, value interface{}, ttl time.Duration) error {
+	var el, ok = c.items[key]
+
+	if ok {
+		if err := c.fill(&el.Value.(*lfuEntry).entry, value, ttl); err != nil {
+			return err
+		}
+
+		el = c.use(el)
+	} else {
+		var e = &lfuEntry{entry: entry{key: key}}
+		if err := c.fill(&e.entry, value, ttl); err != nil {
+			return err
+		}
+
+		el = c.add(e)
+	}
+
+	for c.full() {
+		c.remove(c.victim(el), true)
+	}
+
+	return nil
+}
+
+// Delete removes key from the cache.
+// Returns error if the key is not in the cache.
+func (c *LFU) Delete(key interface{}) error {
+	var el, ok = c.items[key]
+	if !ok {
+		return errorKeyNotFound
+	}
+
+	c.remove(el, false)
+
+	return nil
+}
+
+// Purge removes every expired entry and returns how many there were
+//
+// Time: O(n)
+func (c *LFU) Purge() int {
+	var count int
+
+	c.buckets.Forward(func(b *list.Element) bool {
+		b.Value.(*bucket).entries.Forward(func(el *list.Element) bool {
+			if c.expired(&el.Value.(*lfuEntry).entry) {
+				c.remove(el, true)
+				count++
+			}
+
+			return true
+		})
+
+		return true
+	})
+
+	return count
+}
+
+// Size returns the number of entries in the cache
+func (c *LFU) Size() int {
+	return len(c.items)
+}
+
+// Clear empties the cache
+func (c *LFU) Clear() {
+	c.items = make(map[interface{}]*list.Element)
+	c.buckets.Clear()
+	c.cost = 0
+}
+
+// Helper Methods
+// These methods are used internally.
+
+// add puts a new entry in the bucket for one use, and returns its element
+func (c *LFU) add(e *lfuEntry) *list.Element {
+	var front = c.buckets.Front()
+
+	if front == nil || front.Value.(*bucket).uses != 1 {
+		front = c.buckets.PushFront(&bucket{uses: 1, entries: list.New()})
+	}
+
+	e.bucket = front
+
+	var el = front.Value.(*bucket).entries.PushFront(e)
+	c.items[e.key] = el
+
+	return el
+}
+
+// use moves el to the bucket for one more use, and returns its new element
+func (c *LFU) use(el *list.Element) *list.Element {
+	var e = el.Value.(*lfuEntry)
+	var from = e.bucket
+	var uses = from.Value.(*bucket).uses + 1
+
+	var to = from.Next()
+	if to == nil || to.Value.(*bucket).uses != uses {
+		to, _ = c.buckets.InsertAfter(&bucket{uses: uses, entries: list.New()}, from)
+	}
+
+	c.leave(el)
+
+	e.bucket = to
+	el = to.Value.(*bucket).entries.PushFront(e)
+	c.items[e.key] = el
+
+	return el
+}
+
+// leave takes el out of its bucket, and drops the bucket if that empties it
+func (c *LFU) leave(el *list.Element) {
+	var from = el.Value.(*lfuEntry).bucket
+	var entries = from.Value.(*bucket).entries
+
+	entries.Remove(el)
+
+	if entries.IsEmpty() {
+		c.buckets.Remove(from)
+	}
+}
+
+// victim returns the entry to evict next, passing over keep
+func (c *LFU) victim(keep *list.Element) *list.Element {
+	for b := c.buckets.Front(); b != nil; b = b.Next() {
+		for el := b.Value.(*bucket).entries.Back(); el != nil; el = el.Prev() {
+			if el != keep {
+				return el
+			}
+		}
+	}
+
+	return nil
+}
+
+// remove drops el from the cache
+func (c *LFU) remove(el *list.Element, evicted bool) {
+	var e = el.Value.(*lfuEntry)
+
+	c.leave(el)
+	delete(c.items, e.key)
+	c.dropped(&e.entry, evicted)
+}
@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/noriah/go-code/structure/list"
+)
+
+// LRU implements a Least Recently Used cache.
+// Every use moves an entry to the front of a list, so the entry at the
+// back is the one that has gone unused longest, and is evicted first.
+type LRU struct {
+	base
+	items map[interface{}]*list.Element // Elements in order, by key
+	order *list.List                    // *entry, most recently used at the front
+}
+
+// NewLRU returns a new LRU cache
+func NewLRU(config Config) *LRU {
+	return &LRU{
+		base:  newBase(config),
+		items: make(map[interface{}]*list.Element),
+		order: list.New(),
+	}
+}
+
+// Get returns the value for key, and marks it most recently used.
+//
+// Time: O(1)
+func (c *LRU) Get(key interface{}) (interface{}, error) {
+	var el, ok = c.items[key]
+	if !ok {
+		return nil, errorKeyNotFound
+	}
+
+	var e = el.Value.(*entry)
+
+	if c.expired(e) {
+		c.remove(el, true)
+		return nil, errorKeyNotFound
+	}
+
+	c.order.MoveToFront(el)
+
+	return e.value, nil
+}
+
+// Has returns true if key is in the cache
+func (c *LRU) Has(key interface{}) bool {
+	var el, ok = c.items[key]
+
+	return ok && !c.expired(el.Value.(*entry))
+}
+
+// Put sets the value for key, using the default TTL
+//
+// Time: O(1), plus O(1) per entry evicted
+func (c *LRU) Put(key, value interface{}) error {
+	return c.PutTTL(key, value, c.config.TTL)
+}
+
+// PutTTL sets the value for key, expiring after ttl.
+// Returns error if the entry costs more than the whole capacity.
+//
+// Time: O(1), plus O(1) per entry evicted
+func (c *LRU) PutTTL(key, value interface{}, ttl time.Duration) error {
+	var el, ok = c.items[key]
+
+	if !ok {
+		var e = &entry{key: key}
+		if err := c.fill(e, value, ttl); err != nil {
+			return err
+		}
+
+		el = c.order.PushFront(e)
+		c.items[key] = el
+	} else {
+		if err := c.fill(el.Value.(*entry), value, ttl); err != nil {
+			return err
+		}
+
+		c.order.MoveToFront(el)
+	}
+
+	// The new entry is at the front, so it is the last to go
+	for c.full() {
+		c.remove(c.order.Back(), true)
+	}
+
+	return nil
+}
+
+// Delete removes key from the cache.
+// Returns error if the key is not in the cache.
+func (c *LRU) Delete(key interface{}) error {
+	var el, ok = c.items[key]
+	if !ok {
+		return errorKeyNotFound
+	}
+
+	c.remove(el, false)
+
+	return nil
+}
+
+// Purge removes every expired entry and returns how many there were
+//
+// Time: O(n)
+func (c *LRU) Purge() int {
+	var count int
+
+	c.order.Forward(func(el *list.Element) bool {
+		if c.expired(el.Value.(*entry)) {
+			c.remove(el, true)
+			count++
+		}
+
+		return true
+	})
+
+	return count
+}
+
+// Size returns the number of entries in the cache
+func (c *LRU) Size() int {
+	return len(c.items)
+}
+
+// Clear empties the cache
+func (c *LRU) Clear() {
+	c.items = make(map[interface{}]*list.Element)
+	c.order.Clear()
+	c.cost = 0
+}
+
+// Helper Methods
+// These methods are used internally.
+
+// remove drops el from the cache
+func (c *LRU) remove(el *list.Element, evicted bool) {
+	var e = el.Value.(*entry)
+
+	c.order.Remove(el)
+	delete(c.items, e.key)
+	c.dropped(e, evicted)
+}
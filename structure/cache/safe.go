@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Safe wraps a Cache so it can be shared between goroutines.
+// Every call takes the mutex. OnEvict is called with the mutex held, so it
+// must not call back into the cache.
+type Safe struct {
+	mu    sync.Mutex // Mutex for safe parallel operations
+	cache Cache      // Cache we guard. Nothing else may touch it
+}
+
+// NewSafe returns c wrapped for parallel use
+func NewSafe(c Cache) *Safe {
+	if c == nil {
+		panic("nil cache provided")
+	}
+
+	return &Safe{cache: c}
+}
+
+// Get returns the value for key, and counts as a use of it
+func (s *Safe) Get(key interface{}) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.cache.Get(key)
+}
+
+// Has returns true if key is in the cache
+func (s *Safe) Has(key interface{}) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.cache.Has(key)
+}
+
+// Put sets the value for key, using the default TTL
+func (s *Safe) Put(key, value interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.cache.Put(key, value)
+}
+
+// PutTTL sets the value for key, expiring after ttl
+func (s *Safe) PutTTL(key, value interface{}, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.cache.PutTTL(key, value, ttl)
+}
+
+// Delete removes key from the cache
+func (s *Safe) Delete(key interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.cache.Delete(key)
+}
+
+// Purge removes every expired entry and returns how many there were
+func (s *Safe) Purge() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.cache.Purge()
+}
+
+// Size returns the number of entries in the cache
+func (s *Safe) Size() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.cache.Size()
+}
+
+// Cost returns the total cost of the entries in the cache
+func (s *Safe) Cost() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.cache.Cost()
+}
+
+// Clear empties the cache
+func (s *Safe) Clear() {
+	s.mu.Lock()
+
+	s.cache.Clear()
+
+	s.mu.Unlock()
+}
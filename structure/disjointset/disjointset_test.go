@@ -0,0 +1,345 @@
+package disjointset
+
+import (
+	"math/bits"
+	"math/rand"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// checkForest fails the test if sizes, ranks, circles or the group count
+// disagree with the parent pointers
+func checkForest(t *testing.T, f *forest) {
+	t.Helper()
+
+	var n = len(f.parent)
+	var sizes = make([]int, n)
+	var roots int
+
+	for x := 0; x < n; x++ {
+		var root = x
+		for f.parent[root] != root {
+			if f.rank[f.parent[root]] <= f.rank[root] {
+				t.Fatalf("item %d has rank %d under rank %d", root, f.rank[root], f.rank[f.parent[root]])
+			}
+
+			root = f.parent[root]
+		}
+
+		sizes[root]++
+
+		if f.parent[x] == x {
+			roots++
+		}
+	}
+
+	if roots != f.groups {
+		t.Fatalf("expected %d groups, counted %d", f.groups, roots)
+	}
+
+	for x := 0; x < n; x++ {
+		if f.parent[x] != x {
+			continue
+		}
+
+		if sizes[x] != f.size[x] {
+			t.Fatalf("root %d holds %d items, counted %d", x, f.size[x], sizes[x])
+		}
+
+		// Union by rank: a tree of rank r holds at least 2^r items
+		if 1<<f.rank[x] > sizes[x] {
+			t.Fatalf("root %d has rank %d with only %d items", x, f.rank[x], sizes[x])
+		}
+
+		// Walking the circle visits the whole group and nothing else
+		var count int
+		for y := x; ; {
+			if f.find(y) != x {
+				t.Fatalf("item %d in the circle of %d has root %d", y, x, f.find(y))
+			}
+
+			count++
+
+			if y = f.next[y]; y == x {
+				break
+			}
+		}
+
+		if count != sizes[x] {
+			t.Fatalf("circle of %d has %d items, expected %d", x, count, sizes[x])
+		}
+	}
+}
+
+func TestRandomized(t *testing.T) {
+	const n = 2000
+
+	s := NewInts(n)
+	label := make([]int, n)
+	for i := range label {
+		label[i] = i
+	}
+
+	groups := n
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 3000; i++ {
+		a, b := rng.Intn(n), rng.Intn(n)
+
+		merged, err := s.Union(a, b)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if merged != (label[a] != label[b]) {
+			t.Fatalf("union %d %d: expected %v, got %v", a, b, !merged, merged)
+		}
+
+		if merged {
+			from := label[b]
+			for j := range label {
+				if label[j] == from {
+					label[j] = label[a]
+				}
+			}
+			groups--
+		}
+
+		if i%250 == 0 {
+			checkForest(t, &s.f)
+		}
+	}
+
+	checkForest(t, &s.f)
+
+	if s.Count() != groups {
+		t.Fatalf("expected %d groups, got %d", groups, s.Count())
+	}
+
+	for i := 0; i < 5000; i++ {
+		a, b := rng.Intn(n), rng.Intn(n)
+		if s.Connected(a, b) != (label[a] == label[b]) {
+			t.Fatalf("connected %d %d: expected %v", a, b, label[a] == label[b])
+		}
+	}
+
+	var total int
+	for _, group := range s.Sets() {
+		total += len(group)
+
+		for _, x := range group {
+			if label[x] != label[group[0]] {
+				t.Fatalf("group of %d holds %d", group[0], x)
+			}
+		}
+
+		size, _ := s.SizeOf(group[0])
+		if size != len(group) {
+			t.Fatalf("group of %d has %d items, SizeOf says %d", group[0], len(group), size)
+		}
+	}
+
+	if total != n {
+		t.Fatalf("groups hold %d items, expected %d", total, n)
+	}
+}
+
+func TestHeight(t *testing.T) {
+	const n = 1 << 12
+
+	s := NewInts(n)
+
+	// Merge pairs, then pairs of pairs, building the tallest trees
+	// union by rank allows
+	for step := 1; step < n; step *= 2 {
+		for i := 0; i+step < n; i += 2 * step {
+			s.Union(i, i+step)
+		}
+	}
+
+	checkForest(t, &s.f)
+
+	limit := bits.Len(n) - 1
+	for x := 0; x < n; x++ {
+		height := 0
+		for y := x; s.f.parent[y] != y; y = s.f.parent[y] {
+			height++
+		}
+
+		if height > limit {
+			t.Fatalf("item %d is %d deep, expected at most %d", x, height, limit)
+		}
+	}
+
+	// After a find, the path is flat
+	root, _ := s.Find(n - 1)
+	if s.f.parent[n-1] != root {
+		t.Fatalf("expected %d to point at root %d after find", n-1, root)
+	}
+}
+
+func TestInts(t *testing.T) {
+	s := NewInts(6)
+
+	s.Union(0, 1)
+	s.Union(4, 2)
+	s.Union(1, 4)
+
+	expect := [][]int{{0, 1, 2, 4}, {3}, {5}}
+	got := s.Sets()
+	for _, group := range got {
+		sort.Ints(group)
+	}
+
+	if !reflect.DeepEqual(got, expect) {
+		t.Fatalf("expected %v, got %v", expect, got)
+	}
+
+	members, _ := s.Members(2)
+	if members[0] != 2 || len(members) != 4 {
+		t.Fatalf("expected 4 members starting with 2, got %v", members)
+	}
+
+	if x := s.Add(); x != 6 || s.Size() != 7 || s.Count() != 4 {
+		t.Fatalf("expected item 6 in a group of its own, got %d with %d groups", x, s.Count())
+	}
+
+	if _, err := s.Find(7); err == nil {
+		t.Fatal("expected error finding past the end")
+	}
+
+	if _, err := s.Union(-1, 0); err == nil {
+		t.Fatal("expected error on union with a negative item")
+	}
+
+	if s.Connected(0, 7) {
+		t.Fatal("expected items out of range to be connected to nothing")
+	}
+
+	s.Clear()
+
+	if s.Size() != 7 || s.Count() != 7 || s.Connected(0, 1) {
+		t.Fatal("expected clear to split every group")
+	}
+
+	checkForest(t, &s.f)
+}
+
+func TestSet(t *testing.T) {
+	s := New("a", "b", "c", "a")
+
+	if s.Size() != 3 {
+		t.Fatalf("expected 3 keys, got %d", s.Size())
+	}
+
+	if s.Add("b") {
+		t.Fatal("expected Add of a known key to return false")
+	}
+
+	if !s.Union("a", "c") || s.Union("c", "a") {
+		t.Fatal("expected only the first union to merge")
+	}
+
+	// Union adds keys it has not seen
+	s.Union("d", "e")
+	s.Union(1, "e")
+
+	if s.Size() != 6 || s.Count() != 3 {
+		t.Fatalf("expected 6 keys in 3 groups, got %d in %d", s.Size(), s.Count())
+	}
+
+	expect := [][]interface{}{{"a", "c"}, {"b"}, {"d", "e", 1}}
+	got := s.Sets()
+	for _, group := range got {
+		sort.Slice(group[1:], func(i, j int) bool {
+			return indexOf(s, group[1+i]) < indexOf(s, group[1+j])
+		})
+	}
+
+	if !reflect.DeepEqual(got, expect) {
+		t.Fatalf("expected %v, got %v", expect, got)
+	}
+
+	a, _ := s.Find("a")
+	c, _ := s.Find("c")
+	if a != c {
+		t.Fatalf("expected a and c to share a root, got %v and %v", a, c)
+	}
+
+	if size, _ := s.SizeOf(1); size != 3 {
+		t.Fatalf("expected group of 1 to hold 3, got %d", size)
+	}
+
+	if !s.Connected("d", 1) || s.Connected("a", "b") || s.Connected("a", "z") {
+		t.Fatal("unexpected connectivity")
+	}
+
+	if _, err := s.Find("z"); err == nil {
+		t.Fatal("expected error finding a missing key")
+	}
+
+	if _, err := s.Members("z"); err == nil {
+		t.Fatal("expected error on members of a missing key")
+	}
+
+	checkForest(t, &s.f)
+
+	s.Clear()
+
+	if !s.IsEmpty() || s.Count() != 0 || s.Has("a") {
+		t.Fatal("expected clear to empty the set")
+	}
+}
+
+// indexOf returns where key was added in s
+func indexOf(s *Set, key interface{}) int {
+	return s.index[key]
+}
+
+func TestSetParallel(t *testing.T) {
+	s := New()
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+
+		go func(w int) {
+			defer wg.Done()
+
+			for i := 0; i < 1000; i++ {
+				s.Union(i%100, (i+w)%100)
+				s.Connected(i%100, w)
+			}
+		}(w)
+	}
+
+	wg.Wait()
+
+	checkForest(t, &s.f)
+
+	// Worker 1 alone joins every i to i+1
+	if s.Count() != 1 {
+		t.Fatalf("expected one group, got %d", s.Count())
+	}
+}
+
+func BenchmarkUnionFind(b *testing.B) {
+	const n = 1 << 16
+
+	s := NewInts(n)
+	rng := rand.New(rand.NewSource(1))
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if i%n == 0 {
+			s.Clear()
+		}
+
+		s.Union(rng.Intn(n), rng.Intn(n))
+		s.Find(rng.Intn(n))
+	}
+}
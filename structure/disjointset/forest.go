@@ -0,0 +1,126 @@
+// Package disjointset holds implementations for a Disjoint Set, also
+// called Union-Find. It keeps items split into groups that never overlap,
+// and answers two questions fast: which group is this in, and merge these
+// two groups.
+//
+// Each group is a tree, and the root names the group. Union by rank hangs
+// the shorter tree under the taller, so no tree is taller than log2(n).
+// Path compression points every node it passes straight at the root, so
+// later lookups skip the walk. Together they make each operation
+// O(α(n)), which is at most 4 for any n that fits in memory.
+package disjointset
+
+import "errors"
+
+// An error to be returned when an item is not in the set
+var errorKeyNotFound = errors.New("key not found")
+
+// An error to be returned when an index is past the end of the set
+var errorOutOfRange = errors.New("index out of range")
+
+// forest is the union-find core, on items numbered 0 to n-1.
+// It takes no locks. Ints and Set guard it.
+type forest struct {
+	parent []int   // Parent of each item. Roots are their own parent
+	rank   []uint8 // Upper bound on the height of each root's tree
+	next   []int   // Next item in the same group. Each group is a circle
+	size   []int   // Number of items in each root's group
+	groups int     // Number of groups
+}
+
+// add makes a new item in a group of its own, and returns its number
+func (f *forest) add() int {
+	var x = len(f.parent)
+
+	f.parent = append(f.parent, x)
+	f.rank = append(f.rank, 0)
+	f.next = append(f.next, x)
+	f.size = append(f.size, 1)
+	f.groups++
+
+	return x
+}
+
+// grow adds n items, each in a group of its own
+func (f *forest) grow(n int) {
+	for i := 0; i < n; i++ {
+		f.add()
+	}
+}
+
+// find returns the root of x's group, pointing every item on the way
+// straight at it
+func (f *forest) find(x int) int {
+	var root = x
+	for f.parent[root] != root {
+		root = f.parent[root]
+	}
+
+	for f.parent[x] != root {
+		f.parent[x], x = root, f.parent[x]
+	}
+
+	return root
+}
+
+// union merges the groups of a and b. Returns false if they were one already
+func (f *forest) union(a, b int) bool {
+	var ra, rb = f.find(a), f.find(b)
+	if ra == rb {
+		return false
+	}
+
+	// Hang the shorter tree under the taller
+	if f.rank[ra] < f.rank[rb] {
+		ra, rb = rb, ra
+	}
+
+	f.parent[rb] = ra
+	f.size[ra] += f.size[rb]
+
+	if f.rank[ra] == f.rank[rb] {
+		f.rank[ra]++
+	}
+
+	// Swapping one next pointer from each circle joins them into one
+	f.next[a], f.next[b] = f.next[b], f.next[a]
+
+	f.groups--
+
+	return true
+}
+
+// members returns every item in x's group, starting with x
+func (f *forest) members(x int) []int {
+	var out = make([]int, 0, f.size[f.find(x)])
+
+	for y := x; ; {
+		out = append(out, y)
+
+		if y = f.next[y]; y == x {
+			return out
+		}
+	}
+}
+
+// all returns every group, each ordered from its lowest item, in order of
+// their lowest items
+func (f *forest) all() [][]int {
+	var out = make([][]int, 0, f.groups)
+	var seen = make([]bool, len(f.parent))
+
+	for x := range f.parent {
+		if seen[x] {
+			continue
+		}
+
+		var group = f.members(x)
+		for _, y := range group {
+			seen[y] = true
+		}
+
+		out = append(out, group)
+	}
+
+	return out
+}
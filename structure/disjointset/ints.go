@@ -0,0 +1,154 @@
+package disjointset
+
+import "sync"
+
+// Ints implements a Disjoint Set over the items 0 to n-1
+type Ints struct {
+	mu sync.Mutex // Mutex for safe parallel operations
+	f  forest     // Our groups
+}
+
+// NewInts returns a new Disjoint Set holding the items 0 to n-1,
+// each in a group of its own
+func NewInts(n int) *Ints {
+	if n < 0 {
+		panic("Value less than 0 for n provided")
+	}
+
+	var s = &Ints{}
+	s.f.grow(n)
+
+	return s
+}
+
+// Size returns the number of items in the set
+func (s *Ints) Size() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.f.parent)
+}
+
+// IsEmpty checks for set emptiness
+func (s *Ints) IsEmpty() bool {
+	return s.Size() == 0
+}
+
+// Count returns the number of groups
+func (s *Ints) Count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.f.groups
+}
+
+// Clear puts every item back in a group of its own
+func (s *Ints) Clear() {
+	s.mu.Lock()
+
+	var n = len(s.f.parent)
+	s.f = forest{}
+	s.f.grow(n)
+
+	s.mu.Unlock()
+}
+
+// Add adds a new item in a group of its own, and returns it.
+// It is always the old Size.
+func (s *Ints) Add() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.f.add()
+}
+
+// Find returns the item naming x's group. Two items are in the same group
+// when Find returns the same for both, until the next Union.
+// Returns error if x is out of range.
+//
+// Time: O(α(n)) amortized
+func (s *Ints) Find(x int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.has(x) {
+		return -1, errorOutOfRange
+	}
+
+	return s.f.find(x), nil
+}
+
+// Union merges the groups of a and b. Returns false if they were one already.
+// Returns error if either is out of range.
+//
+// Time: O(α(n)) amortized
+func (s *Ints) Union(a, b int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.has(a) || !s.has(b) {
+		return false, errorOutOfRange
+	}
+
+	return s.f.union(a, b), nil
+}
+
+// Connected returns true if a and b are in the same group.
+// Items out of range are in no group.
+//
+// Time: O(α(n)) amortized
+func (s *Ints) Connected(a, b int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.has(a) && s.has(b) && s.f.find(a) == s.f.find(b)
+}
+
+// SizeOf returns the number of items in x's group.
+// Returns error if x is out of range.
+//
+// Time: O(α(n)) amortized
+func (s *Ints) SizeOf(x int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.has(x) {
+		return 0, errorOutOfRange
+	}
+
+	return s.f.size[s.f.find(x)], nil
+}
+
+// Members returns every item in x's group, starting with x.
+// Returns error if x is out of range.
+//
+// Time: O(m) where m is the size of the group
+func (s *Ints) Members(x int) ([]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.has(x) {
+		return nil, errorOutOfRange
+	}
+
+	return s.f.members(x), nil
+}
+
+// Sets returns every group. Each starts with its lowest item, and they come
+// in order of those.
+//
+// Time: O(n)
+func (s *Ints) Sets() [][]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.f.all()
+}
+
+// Helper Methods
+// These methods are used internally.
+
+// has returns true if x is an item. The mutex must be held.
+func (s *Ints) has(x int) bool {
+	return x >= 0 && x < len(s.f.parent)
+}
@@ -0,0 +1,195 @@
+package disjointset
+
+import "sync"
+
+// Set implements a Disjoint Set over any keys that can be map keys
+type Set struct {
+	mu    sync.Mutex          // Mutex for safe parallel operations
+	f     forest              // Our groups, on the index of each key
+	index map[interface{}]int // Index of each key in f
+	keys  []interface{}       // Key at each index in f
+}
+
+// New returns a new Disjoint Set holding keys, each in a group of its own.
+// Keys already added are skipped.
+func New(keys ...interface{}) *Set {
+	var s = &Set{index: make(map[interface{}]int, len(keys))}
+
+	for _, key := range keys {
+		s.add(key)
+	}
+
+	return s
+}
+
+// Size returns the number of keys in the set
+func (s *Set) Size() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.keys)
+}
+
+// IsEmpty checks for set emptiness
+func (s *Set) IsEmpty() bool {
+	return s.Size() == 0
+}
+
+// Count returns the number of groups
+func (s *Set) Count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.f.groups
+}
+
+// Clear removes every key from the set
+func (s *Set) Clear() {
+	s.mu.Lock()
+
+	s.f = forest{}
+	s.index = make(map[interface{}]int)
+	s.keys = nil
+
+	s.mu.Unlock()
+}
+
+// Add adds key in a group of its own.
+// Returns false if key is already in the set, leaving its group alone.
+func (s *Set) Add(key interface{}) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.add(key)
+}
+
+// Has returns true if key is in the set
+func (s *Set) Has(key interface{}) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var _, ok = s.index[key]
+
+	return ok
+}
+
+// Find returns the key naming key's group. Two keys are in the same group
+// when Find returns the same for both, until the next Union.
+// Returns nil and error if key is not in the set.
+//
+// Time: O(α(n)) amortized
+func (s *Set) Find(key interface{}) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var x, ok = s.index[key]
+	if !ok {
+		return nil, errorKeyNotFound
+	}
+
+	return s.keys[s.f.find(x)], nil
+}
+
+// Union merges the groups of a and b, adding either if it is new.
+// Returns false if they were one already.
+//
+// Time: O(α(n)) amortized
+func (s *Set) Union(a, b interface{}) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.add(a)
+	s.add(b)
+
+	return s.f.union(s.index[a], s.index[b])
+}
+
+// Connected returns true if a and b are in the same group.
+// Keys not in the set are in no group.
+//
+// Time: O(α(n)) amortized
+func (s *Set) Connected(a, b interface{}) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var x, okA = s.index[a]
+	var y, okB = s.index[b]
+
+	return okA && okB && s.f.find(x) == s.f.find(y)
+}
+
+// SizeOf returns the number of keys in key's group.
+// Returns error if key is not in the set.
+//
+// Time: O(α(n)) amortized
+func (s *Set) SizeOf(key interface{}) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var x, ok = s.index[key]
+	if !ok {
+		return 0, errorKeyNotFound
+	}
+
+	return s.f.size[s.f.find(x)], nil
+}
+
+// Members returns every key in key's group, starting with key.
+// Returns nil and error if key is not in the set.
+//
+// Time: O(m) where m is the size of the group
+func (s *Set) Members(key interface{}) ([]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var x, ok = s.index[key]
+	if !ok {
+		return nil, errorKeyNotFound
+	}
+
+	return s.lookup(s.f.members(x)), nil
+}
+
+// Sets returns every group. Each starts with the first of its keys to be
+// added, and they come in order of those.
+//
+// Time: O(n)
+func (s *Set) Sets() [][]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var groups = s.f.all()
+	var out = make([][]interface{}, len(groups))
+
+	for i, group := range groups {
+		out[i] = s.lookup(group)
+	}
+
+	return out
+}
+
+// Helper Methods
+// These methods are used internally.
+
+// add adds key in a group of its own, if it is new. The mutex must be held.
+func (s *Set) add(key interface{}) bool {
+	if _, ok := s.index[key]; ok {
+		return false
+	}
+
+	s.index[key] = s.f.add()
+	s.keys = append(s.keys, key)
+
+	return true
+}
+
+// lookup returns the keys at indexes
+func (s *Set) lookup(indexes []int) []interface{} {
+	var out = make([]interface{}, len(indexes))
+
+	for i, x := range indexes {
+		out[i] = s.keys[x]
+	}
+
+	return out
+}
@@ -0,0 +1,143 @@
+package filter
+
+import (
+	"encoding/binary"
+	"math"
+	"sync"
+)
+
+// Bloom implements a Bloom Filter.
+// Adding an item sets k bits, picked by hashing it. Testing an item checks
+// those k bits. If any is clear the item was never added. If all are set
+// it probably was, unless other items happened to set them all.
+type Bloom struct {
+	mu    sync.Mutex // Mutex for safe parallel operations
+	bits  []uint64   // Bit array, 64 to a word
+	m     uint64     // Number of bits in use
+	k     uint32     // Number of bits set per item
+	count uint64     // Number of items added
+}
+
+// NewBloom returns a Bloom Filter sized to hold n items with a false
+// positive rate of p
+func NewBloom(n int, p float64) *Bloom {
+	var m, k = optimal(n, p)
+
+	return &Bloom{
+		bits: make([]uint64, (m+63)/64),
+		m:    m,
+		k:    k,
+	}
+}
+
+// Add records data in the filter.
+//
+// Time: O(k)
+func (b *Bloom) Add(data []byte) {
+	var h1, h2 = hashes(data)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i := uint64(0); i < uint64(b.k); i++ {
+		var bit = (h1 + i*h2) % b.m
+		b.bits[bit/64] |= 1 << (bit % 64)
+	}
+
+	b.count++
+}
+
+// Has returns false if data was never added, and true if it probably was.
+//
+// Time: O(k)
+func (b *Bloom) Has(data []byte) bool {
+	var h1, h2 = hashes(data)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i := uint64(0); i < uint64(b.k); i++ {
+		var bit = (h1 + i*h2) % b.m
+		if b.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Count returns the number of times Add was called
+func (b *Bloom) Count() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return int(b.count)
+}
+
+// FalsePositiveRate returns the expected false positive rate for the
+// items added so far
+func (b *Bloom) FalsePositiveRate() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return math.Pow(1-math.Exp(-float64(b.k)*float64(b.count)/float64(b.m)), float64(b.k))
+}
+
+// Clear empties the filter
+func (b *Bloom) Clear() {
+	b.mu.Lock()
+
+	for i := range b.bits {
+		b.bits[i] = 0
+	}
+
+	b.count = 0
+
+	b.mu.Unlock()
+}
+
+// MarshalBinary returns the filter as bytes
+func (b *Bloom) MarshalBinary() ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var buf = header('B', 24+8*len(b.bits))
+	buf = appendUint64(buf, b.m)
+	buf = appendUint64(buf, uint64(b.k))
+	buf = appendUint64(buf, b.count)
+
+	for _, word := range b.bits {
+		buf = appendUint64(buf, word)
+	}
+
+	return buf, nil
+}
+
+// UnmarshalBinary replaces the filter with one from MarshalBinary
+func (b *Bloom) UnmarshalBinary(data []byte) error {
+	var r = open(data, 'B')
+	var m, k, count = r.uint64(), r.uint64(), r.uint64()
+
+	// m must fit the bytes left before we size anything by it, and k is the
+	// work done on every lookup
+	if r.bad || m == 0 || m > r.left()*8 || k == 0 || k > m || k > maxHashes {
+		return errorBadData
+	}
+
+	var words = r.take((m + 63) / 64 * 8)
+	if err := r.err(); err != nil {
+		return err
+	}
+
+	var bits = make([]uint64, len(words)/8)
+	for i := range bits {
+		bits[i] = binary.BigEndian.Uint64(words[i*8:])
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.bits, b.m, b.k, b.count = bits, m, uint32(k), count
+
+	return nil
+}
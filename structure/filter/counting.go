@@ -0,0 +1,181 @@
+package filter
+
+import (
+	"sync"
+)
+
+// The highest a counter goes. Past this it sticks, since we no longer
+// know how many items share it
+const maxCounter = 15
+
+// Counting implements a Counting Bloom Filter.
+// It is a Bloom Filter with a 4 bit counter in place of each bit. Adding
+// an item counts its k counters up, and removing it counts them back down,
+// so unlike a plain Bloom Filter, items can be removed.
+//
+// It takes four times the memory of a Bloom Filter with the same rate.
+type Counting struct {
+	mu       sync.Mutex // Mutex for safe parallel operations
+	counters []byte     // Counters, two to a byte. Even ones in the low half
+	m        uint64     // Number of counters in use
+	k        uint32     // Number of counters per item
+	count    uint64     // Number of items held
+}
+
+// NewCounting returns a Counting Bloom Filter sized to hold n items with
+// a false positive rate of p
+func NewCounting(n int, p float64) *Counting {
+	var m, k = optimal(n, p)
+
+	return &Counting{
+		counters: make([]byte, (m+1)/2),
+		m:        m,
+		k:        k,
+	}
+}
+
+// Add records data in the filter.
+//
+// Time: O(k)
+func (c *Counting) Add(data []byte) {
+	var h1, h2 = hashes(data)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i := uint64(0); i < uint64(c.k); i++ {
+		var at = (h1 + i*h2) % c.m
+		if v := c.get(at); v < maxCounter {
+			c.set(at, v+1)
+		}
+	}
+
+	c.count++
+}
+
+// Has returns false if data is not in the filter, and true if it
+// probably is.
+//
+// Time: O(k)
+func (c *Counting) Has(data []byte) bool {
+	var h1, h2 = hashes(data)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.has(h1, h2)
+}
+
+// Remove takes data back out of the filter.
+// Returns error if data is not in the filter. Removing something that
+// was never added, but tests as present, removes a bit of something else
+// and can cause false negatives.
+//
+// Time: O(k)
+func (c *Counting) Remove(data []byte) error {
+	var h1, h2 = hashes(data)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.has(h1, h2) {
+		return errorNotPresent
+	}
+
+	for i := uint64(0); i < uint64(c.k); i++ {
+		var at = (h1 + i*h2) % c.m
+
+		// A stuck counter might be covering more items than we know
+		if v := c.get(at); v < maxCounter {
+			c.set(at, v-1)
+		}
+	}
+
+	c.count--
+
+	return nil
+}
+
+// Count returns the number of items in the filter
+func (c *Counting) Count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return int(c.count)
+}
+
+// Clear empties the filter
+func (c *Counting) Clear() {
+	c.mu.Lock()
+
+	for i := range c.counters {
+		c.counters[i] = 0
+	}
+
+	c.count = 0
+
+	c.mu.Unlock()
+}
+
+// MarshalBinary returns the filter as bytes
+func (c *Counting) MarshalBinary() ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var buf = header('C', 24+len(c.counters))
+	buf = appendUint64(buf, c.m)
+	buf = appendUint64(buf, uint64(c.k))
+	buf = appendUint64(buf, c.count)
+
+	return append(buf, c.counters...), nil
+}
+
+// UnmarshalBinary replaces the filter with one from MarshalBinary
+func (c *Counting) UnmarshalBinary(data []byte) error {
+	var r = open(data, 'C')
+	var m, k, count = r.uint64(), r.uint64(), r.uint64()
+
+	// m must fit the bytes left before we size anything by it, and k is the
+	// work done on every lookup
+	if r.bad || m == 0 || m > r.left()*2 || k == 0 || k > m || k > maxHashes {
+		return errorBadData
+	}
+
+	var counters = r.take((m + 1) / 2)
+	if err := r.err(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.counters = append([]byte(nil), counters...)
+	c.m, c.k, c.count = m, uint32(k), count
+
+	return nil
+}
+
+// Helper Methods
+// These methods are used internally.
+
+// has returns true if every counter for the hashes is above zero
+func (c *Counting) has(h1, h2 uint64) bool {
+	for i := uint64(0); i < uint64(c.k); i++ {
+		if c.get((h1+i*h2)%c.m) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// get returns counter i
+func (c *Counting) get(i uint64) byte {
+	return c.counters[i/2] >> (4 * (i % 2)) & 0xf
+}
+
+// set stores v in counter i
+func (c *Counting) set(i uint64, v byte) {
+	var shift = 4 * (i % 2)
+	c.counters[i/2] = c.counters[i/2]&^(0xf<<shift) | v<<shift
+}
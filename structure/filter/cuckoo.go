@@ -0,0 +1,301 @@
+package filter
+
+import (
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"sync"
+)
+
+// Fingerprints held in each bucket
+const bucketSize = 4
+
+// How many fingerprints Add moves around looking for room before giving up
+const maxKicks = 500
+
+// The share of slots a cuckoo filter can fill before Adds start to fail
+const cuckooLoad = 0.95
+
+// Cuckoo implements a Cuckoo Filter (Fan et al., 2014).
+// Each item is stored as a 16 bit fingerprint in one of two buckets. The
+// second bucket comes from the first and the fingerprint alone, so a
+// fingerprint can be moved between its two buckets without the item.
+// When both are full, Add kicks a fingerprint out to its other bucket,
+// which may kick out another, like a cuckoo chick.
+//
+// Unlike a Bloom Filter, items can be removed, and at low false positive
+// rates it takes less memory. Unlike a Bloom Filter it can also fill up.
+type Cuckoo struct {
+	mu       sync.Mutex // Mutex for safe parallel operations
+	slots    []uint16   // Fingerprints, bucketSize to a bucket. 0 is empty
+	mask     uint64     // Number of buckets minus one. Buckets are a power of two
+	count    uint64     // Number of items held
+	victim   uint16     // Fingerprint kicked out with nowhere to go. 0 means none
+	victimAt uint64     // One of the victim's two buckets
+	rng      *rand.Rand // Picks which fingerprint to kick
+}
+
+// NewCuckoo returns a Cuckoo Filter with room for n items
+func NewCuckoo(n int) *Cuckoo {
+	if n < 1 {
+		panic("Value less than 1 for items provided")
+	}
+
+	var buckets = uint64(1)
+	for float64(buckets*bucketSize)*cuckooLoad < float64(n) {
+		buckets *= 2
+	}
+
+	return &Cuckoo{
+		slots: make([]uint16, buckets*bucketSize),
+		mask:  buckets - 1,
+		rng:   rand.New(rand.NewSource(1)),
+	}
+}
+
+// Add records data in the filter.
+// Returns error if the filter is full. Adding the same data twice stores
+// it twice, and it then takes two Removes to take it out.
+//
+// Time: O(1) expected
+func (c *Cuckoo) Add(data []byte) error {
+	var fp, h = fingerprint(data)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var i1, i2 = c.buckets(fp, h)
+
+	// The last Add found no room. Until a Remove makes some, we are full
+	if c.victim != 0 {
+		return errorFilterFull
+	}
+
+	if c.put(i1, fp) || c.put(i2, fp) {
+		c.count++
+		return nil
+	}
+
+	// Both full. Kick fingerprints along until one lands in a free slot
+	var i = i1
+	if c.rng.Intn(2) == 0 {
+		i = i2
+	}
+
+	for n := 0; n < maxKicks; n++ {
+		var slot = i*bucketSize + uint64(c.rng.Intn(bucketSize))
+		fp, c.slots[slot] = c.slots[slot], fp
+		i = c.alternate(i, fp)
+
+		if c.put(i, fp) {
+			c.count++
+			return nil
+		}
+	}
+
+	// Out of kicks. Hold the one left over, so nothing is lost
+	c.victim, c.victimAt = fp, i
+	c.count++
+
+	return nil
+}
+
+// Has returns false if data is not in the filter, and true if it
+// probably is.
+//
+// Time: O(1)
+func (c *Cuckoo) Has(data []byte) bool {
+	var fp, h = fingerprint(data)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var i1, i2 = c.buckets(fp, h)
+
+	if c.victim == fp && (c.victimAt == i1 || c.victimAt == i2) {
+		return true
+	}
+
+	return c.find(i1, fp) >= 0 || c.find(i2, fp) >= 0
+}
+
+// Remove takes data back out of the filter.
+// Returns error if data is not in the filter. Removing something that
+// was never added, but tests as present, removes something else.
+//
+// Time: O(1) expected
+func (c *Cuckoo) Remove(data []byte) error {
+	var fp, h = fingerprint(data)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var i1, i2 = c.buckets(fp, h)
+
+	switch {
+	case c.victim == fp && (c.victimAt == i1 || c.victimAt == i2):
+		c.victim = 0
+
+	case c.drop(i1, fp) || c.drop(i2, fp):
+		// There is room now. Give the victim a home if it fits
+		if c.victim != 0 && (c.put(c.victimAt, c.victim) || c.put(c.alternate(c.victimAt, c.victim), c.victim)) {
+			c.victim = 0
+		}
+
+	default:
+		return errorNotPresent
+	}
+
+	c.count--
+
+	return nil
+}
+
+// Count returns the number of items in the filter
+func (c *Cuckoo) Count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return int(c.count)
+}
+
+// FalsePositiveRate returns the expected false positive rate for the
+// items held now. A lookup checks two buckets, and each fingerprint in
+// them has a 1 in 65535 chance to match.
+func (c *Cuckoo) FalsePositiveRate() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var load = float64(c.count) / float64(len(c.slots))
+
+	return 1 - math.Pow(1-1.0/65535, 2*bucketSize*load)
+}
+
+// Clear empties the filter
+func (c *Cuckoo) Clear() {
+	c.mu.Lock()
+
+	for i := range c.slots {
+		c.slots[i] = 0
+	}
+
+	c.count = 0
+	c.victim = 0
+
+	c.mu.Unlock()
+}
+
+// MarshalBinary returns the filter as bytes
+func (c *Cuckoo) MarshalBinary() ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var buf = header('K', 32+2*len(c.slots))
+	buf = appendUint64(buf, c.mask+1)
+	buf = appendUint64(buf, c.count)
+	buf = appendUint64(buf, uint64(c.victim))
+	buf = appendUint64(buf, c.victimAt)
+
+	var b [2]byte
+	for _, fp := range c.slots {
+		binary.BigEndian.PutUint16(b[:], fp)
+		buf = append(buf, b[:]...)
+	}
+
+	return buf, nil
+}
+
+// UnmarshalBinary replaces the filter with one from MarshalBinary
+func (c *Cuckoo) UnmarshalBinary(data []byte) error {
+	var r = open(data, 'K')
+	var buckets, count, victim, victimAt = r.uint64(), r.uint64(), r.uint64(), r.uint64()
+
+	// Buckets must be a power of two that fits the bytes left, and the
+	// victim must fit
+	if r.bad || buckets == 0 || buckets&(buckets-1) != 0 || buckets > r.left()/(bucketSize*2) ||
+		victim > math.MaxUint16 || victimAt >= buckets {
+		return errorBadData
+	}
+
+	var raw = r.take(buckets * bucketSize * 2)
+	if err := r.err(); err != nil {
+		return err
+	}
+
+	var slots = make([]uint16, buckets*bucketSize)
+	for i := range slots {
+		slots[i] = binary.BigEndian.Uint16(raw[i*2:])
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.slots, c.mask, c.count = slots, buckets-1, count
+	c.victim, c.victimAt = uint16(victim), victimAt
+
+	if c.rng == nil {
+		c.rng = rand.New(rand.NewSource(1))
+	}
+
+	return nil
+}
+
+// Helper Methods
+// These methods are used internally.
+
+// fingerprint returns the fingerprint for data, and the hash that picks
+// its first bucket
+func fingerprint(data []byte) (uint16, uint64) {
+	var h, _ = hashes(data)
+
+	// 0 marks an empty slot, so it cannot be a fingerprint. Spread the top
+	// bits evenly over 1..65535 rather than moving 0 onto 1, which would
+	// make 1 twice as likely as the rest
+	var fp = 1 + uint16((h>>32)%65535)
+
+	return fp, h
+}
+
+// buckets returns the two buckets fp may be in
+func (c *Cuckoo) buckets(fp uint16, h uint64) (uint64, uint64) {
+	var i1 = h & c.mask
+
+	return i1, c.alternate(i1, fp)
+}
+
+// alternate returns the other bucket for fp, given one of them. Applying
+// it twice gets back where you started
+func (c *Cuckoo) alternate(i uint64, fp uint16) uint64 {
+	return (i ^ mix(uint64(fp))) & c.mask
+}
+
+// find returns the slot in bucket i holding fp, or -1
+func (c *Cuckoo) find(i uint64, fp uint16) int {
+	for s := i * bucketSize; s < (i+1)*bucketSize; s++ {
+		if c.slots[s] == fp {
+			return int(s)
+		}
+	}
+
+	return -1
+}
+
+// put stores fp in a free slot of bucket i. Returns false if it is full
+func (c *Cuckoo) put(i uint64, fp uint16) bool {
+	if s := c.find(i, 0); s >= 0 {
+		c.slots[s] = fp
+		return true
+	}
+
+	return false
+}
+
+// drop clears one copy of fp from bucket i. Returns false if it is not there
+func (c *Cuckoo) drop(i uint64, fp uint16) bool {
+	if s := c.find(i, fp); s >= 0 {
+		c.slots[s] = 0
+		return true
+	}
+
+	return false
+}
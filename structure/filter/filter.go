@@ -0,0 +1,153 @@
+// Package filter holds implementations for probabilistic set filters.
+// A filter answers "have I seen this?" in a fraction of the memory a set
+// would take. It never says no to something it has seen, but now and then
+// says yes to something it has not. That false positive rate is chosen up
+// front, and traded against size.
+//
+// Every filter can be saved with MarshalBinary and loaded back with
+// UnmarshalBinary.
+package filter
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"math"
+)
+
+// Version of the byte format written by MarshalBinary
+const formatVersion = 1
+
+// Most hashes a Bloom or Counting filter uses per item. 64 already means a
+// false positive rate near 2^-64, and a bigger k from UnmarshalBinary would
+// only make every lookup slow
+const maxHashes = 64
+
+// An error to be returned when UnmarshalBinary is given bytes it did not write
+var errorBadData = errors.New("bad filter data")
+
+// An error to be returned when Remove-ing something the filter has not seen
+var errorNotPresent = errors.New("not in filter")
+
+// An error to be returned when a cuckoo filter has no room left
+var errorFilterFull = errors.New("full filter")
+
+// Helper Methods
+// These methods are used internally.
+
+// hashes returns two independent hashes of data. Any number of hashes
+// can be made from them as h1 + i*h2 (Kirsch and Mitzenmacher, 2006)
+func hashes(data []byte) (uint64, uint64) {
+	var h = fnv.New64a()
+	h.Write(data)
+
+	var h1 = mix(h.Sum64())
+	var h2 = mix(h1) | 1 // Odd, so it never gets stuck on one bit
+
+	return h1, h2
+}
+
+// mix spreads every input bit over every output bit (the splitmix64
+// finalizer)
+func mix(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+
+	return h
+}
+
+// optimal returns the number of bits m and hashes k that hold n items at
+// false positive rate p with the least memory
+func optimal(n int, p float64) (uint64, uint32) {
+	if n < 1 {
+		panic("Value less than 1 for items provided")
+	}
+
+	if p <= 0 || p >= 1 {
+		panic("False positive rate not between 0 and 1 provided")
+	}
+
+	var m = math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2))
+	var k = math.Round(m / float64(n) * math.Ln2)
+
+	if k < 1 {
+		k = 1
+	}
+
+	if k > maxHashes {
+		k = maxHashes
+	}
+
+	return uint64(m), uint32(k)
+}
+
+// header starts a marshalled filter with its kind and format version
+func header(kind byte, size int) []byte {
+	var buf = make([]byte, 2, 2+size)
+	buf[0], buf[1] = kind, formatVersion
+
+	return buf
+}
+
+// appendUint64 adds v to buf, big endian
+func appendUint64(buf []byte, v uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+
+	return append(buf, b[:]...)
+}
+
+// reader pulls fields out of marshalled bytes. Once anything is short,
+// every read returns zero and bad is set
+type reader struct {
+	data []byte
+	bad  bool
+}
+
+// open checks the kind and version, and returns a reader for the rest
+func open(data []byte, kind byte) *reader {
+	if len(data) < 2 || data[0] != kind || data[1] != formatVersion {
+		return &reader{bad: true}
+	}
+
+	return &reader{data: data[2:]}
+}
+
+// take returns the next n bytes
+func (r *reader) take(n uint64) []byte {
+	if r.bad || uint64(len(r.data)) < n {
+		r.bad = true
+		return nil
+	}
+
+	var out = r.data[:n]
+	r.data = r.data[n:]
+
+	return out
+}
+
+// uint64 reads a big endian uint64
+func (r *reader) uint64() uint64 {
+	if b := r.take(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+
+	return 0
+}
+
+// left returns the number of bytes not yet read
+func (r *reader) left() uint64 {
+	return uint64(len(r.data))
+}
+
+// err returns errorBadData if anything was short, or bytes are left over
+func (r *reader) err() error {
+	if r.bad || len(r.data) != 0 {
+		return errorBadData
+	}
+
+	return nil
+}
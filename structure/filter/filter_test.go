@@ -0,0 +1,314 @@
+package filter
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"math"
+	"testing"
+)
+
+const (
+	items   = 10000
+	queries = 200000
+)
+
+func key(prefix string, i int) []byte {
+	return []byte(fmt.Sprintf("%s-%d", prefix, i))
+}
+
+// falsePositives returns the share of keys never added that has says
+// are present
+func falsePositives(has func([]byte) bool, prefix string) float64 {
+	var hits int
+	for i := 0; i < queries; i++ {
+		if has(key(prefix, i)) {
+			hits++
+		}
+	}
+
+	return float64(hits) / queries
+}
+
+func TestBloom(t *testing.T) {
+	for _, p := range []float64{0.05, 0.01, 0.001} {
+		b := NewBloom(items, p)
+
+		for i := 0; i < items; i++ {
+			b.Add(key("in", i))
+		}
+
+		for i := 0; i < items; i++ {
+			if !b.Has(key("in", i)) {
+				t.Fatalf("p=%g: false negative for %s", p, key("in", i))
+			}
+		}
+
+		rate := falsePositives(b.Has, "out")
+		if rate > 1.5*p {
+			t.Errorf("p=%g: false positive rate %g is over the bound %g", p, rate, 1.5*p)
+		}
+
+		if expect := b.FalsePositiveRate(); expect > 1.1*p {
+			t.Errorf("p=%g: expected rate %g once full", p, expect)
+		}
+
+		b.Clear()
+		if b.Has(key("in", 0)) || b.Count() != 0 {
+			t.Errorf("p=%g: expected an empty filter after Clear", p)
+		}
+	}
+}
+
+func TestCounting(t *testing.T) {
+	const p = 0.01
+
+	c := NewCounting(items, p)
+
+	for i := 0; i < items; i++ {
+		c.Add(key("in", i))
+	}
+
+	if rate := falsePositives(c.Has, "out"); rate > 1.5*p {
+		t.Errorf("false positive rate %g is over the bound %g", rate, 1.5*p)
+	}
+
+	// Take out the odd ones
+	for i := 1; i < items; i += 2 {
+		if err := c.Remove(key("in", i)); err != nil {
+			t.Fatalf("Remove(%s): %v", key("in", i), err)
+		}
+	}
+
+	if c.Count() != items/2 {
+		t.Errorf("expected %d items, got %d", items/2, c.Count())
+	}
+
+	for i := 0; i < items; i += 2 {
+		if !c.Has(key("in", i)) {
+			t.Fatalf("false negative for %s after removing others", key("in", i))
+		}
+	}
+
+	// Removed items now look like items never added, at half the load
+	var hits int
+	for i := 1; i < items; i += 2 {
+		if c.Has(key("in", i)) {
+			hits++
+		}
+	}
+
+	if rate := float64(hits) / (items / 2); rate > 1.5*p {
+		t.Errorf("removed items still present at rate %g, over the bound %g", rate, 1.5*p)
+	}
+
+	var missing int
+	for i := 0; i < 100; i++ {
+		if c.Remove(key("never", i)) == errorNotPresent {
+			missing++
+		}
+	}
+
+	if missing < 90 {
+		t.Errorf("expected most Removes of unseen keys to fail, %d of 100 did", missing)
+	}
+}
+
+func TestCuckoo(t *testing.T) {
+	c := NewCuckoo(items)
+
+	for i := 0; i < items; i++ {
+		if err := c.Add(key("in", i)); err != nil {
+			t.Fatalf("Add(%s): %v", key("in", i), err)
+		}
+	}
+
+	for i := 0; i < items; i++ {
+		if !c.Has(key("in", i)) {
+			t.Fatalf("false negative for %s", key("in", i))
+		}
+	}
+
+	// Two buckets of bucketSize fingerprints, each matching 1 in 65535,
+	// even with every slot full. Twice that leaves room for noise
+	bound := 2 * (2 * bucketSize / 65535.0)
+	if rate := falsePositives(c.Has, "out"); rate > bound {
+		t.Errorf("false positive rate %g is over the bound %g", rate, bound)
+	}
+
+	for i := 0; i < items; i++ {
+		if err := c.Remove(key("in", i)); err != nil {
+			t.Fatalf("Remove(%s): %v", key("in", i), err)
+		}
+	}
+
+	if c.Count() != 0 {
+		t.Errorf("expected an empty filter, got %d items", c.Count())
+	}
+
+	if err := c.Remove(key("in", 0)); err != errorNotPresent {
+		t.Errorf("expected %v, got %v", errorNotPresent, err)
+	}
+}
+
+func TestCuckooFull(t *testing.T) {
+	c := NewCuckoo(100)
+
+	var added int
+	for ; added < 10000; added++ {
+		if err := c.Add(key("in", added)); err == errorFilterFull {
+			break
+		}
+	}
+
+	if added == 10000 {
+		t.Fatalf("expected the filter to fill up")
+	}
+
+	// Every Add that succeeded, including the one left as the victim, is still there
+	for i := 0; i < added; i++ {
+		if !c.Has(key("in", i)) {
+			t.Fatalf("false negative for %s in a full filter", key("in", i))
+		}
+	}
+
+	// Making room lets Adds through again
+	c.Remove(key("in", 0))
+	c.Remove(key("in", 1))
+
+	if err := c.Add(key("again", 0)); err != nil {
+		t.Errorf("expected room after Remove, got %v", err)
+	}
+}
+
+// filter is what every filter has in common
+type filter interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+	Has(data []byte) bool
+}
+
+func TestMarshal(t *testing.T) {
+	bloom, counting, cuckoo := NewBloom(1000, 0.01), NewCounting(1000, 0.01), NewCuckoo(1000)
+
+	for i := 0; i < 1000; i++ {
+		bloom.Add(key("in", i))
+		counting.Add(key("in", i))
+		cuckoo.Add(key("in", i))
+	}
+
+	tests := []struct {
+		name     string
+		filter   filter
+		restored filter
+	}{
+		{"Bloom", bloom, &Bloom{}},
+		{"Counting", counting, &Counting{}},
+		{"Cuckoo", cuckoo, &Cuckoo{}},
+
+		// A tiny rate wants more than maxHashes, and must still load
+		{"Bloom", NewBloom(10, 1e-30), &Bloom{}},
+		{"Counting", NewCounting(10, 1e-30), &Counting{}},
+	}
+
+	for _, test := range tests {
+		data, err := test.filter.MarshalBinary()
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		if err := test.restored.UnmarshalBinary(data); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		again, _ := test.restored.MarshalBinary()
+		if !bytes.Equal(data, again) {
+			t.Errorf("%s: marshalling the restored filter gave different bytes", test.name)
+		}
+
+		for i := 0; i < 2000; i++ {
+			if test.filter.Has(key("in", i)) != test.restored.Has(key("in", i)) {
+				t.Fatalf("%s: restored filter disagrees on %s", test.name, key("in", i))
+			}
+		}
+
+		bad := [][]byte{
+			nil,
+			data[:len(data)-1],
+			append(append([]byte(nil), data...), 0),
+			append([]byte{'x'}, data[1:]...),
+			append([]byte{data[0], formatVersion + 1}, data[2:]...),
+		}
+
+		for i, b := range bad {
+			if err := test.restored.UnmarshalBinary(b); err != errorBadData {
+				t.Errorf("%s: bad data %d: expected %v, got %v", test.name, i, errorBadData, err)
+			}
+		}
+	}
+}
+
+func TestUnmarshalSizes(t *testing.T) {
+	// craft returns a header of fields, followed by size bytes of data
+	craft := func(kind byte, size int, fields ...uint64) []byte {
+		buf := header(kind, 8*len(fields)+size)
+		for _, f := range fields {
+			buf = appendUint64(buf, f)
+		}
+		return append(buf, make([]byte, size)...)
+	}
+
+	tests := []struct {
+		name   string
+		filter filter
+		data   []byte
+	}{
+		// Sizes that wrap to 0 bytes when rounded up
+		{"Bloom", &Bloom{}, craft('B', 0, math.MaxUint64, 3, 0)},
+		{"Bloom", &Bloom{}, craft('B', 0, math.MaxUint64-62, 3, 0)},
+		{"Counting", &Counting{}, craft('C', 0, math.MaxUint64, 3, 0)},
+		{"Cuckoo", &Cuckoo{}, craft('K', 0, 1<<62, 0, 0, 0)},
+		{"Cuckoo", &Cuckoo{}, craft('K', 0, 1<<61, 0, 0, 0)},
+
+		// Sizes one past what the data holds
+		{"Bloom", &Bloom{}, craft('B', 8, 65, 3, 0)},
+		{"Counting", &Counting{}, craft('C', 8, 17, 3, 0)},
+		{"Cuckoo", &Cuckoo{}, craft('K', 8, 2, 0, 0, 0)},
+
+		// More hashes than bits, or than any filter needs
+		{"Bloom", &Bloom{}, craft('B', 8, 64, 65, 0)},
+		{"Bloom", &Bloom{}, craft('B', 8, 64, 1<<32-1, 0)},
+		{"Bloom", &Bloom{}, craft('B', 8, 1, 2, 0)},
+		{"Counting", &Counting{}, craft('C', 8, 16, 65, 0)},
+		{"Counting", &Counting{}, craft('C', 8, 16, 1<<32-1, 0)},
+		{"Counting", &Counting{}, craft('C', 8, 1, 2, 0)},
+	}
+
+	for i, test := range tests {
+		if err := test.filter.UnmarshalBinary(test.data); err != errorBadData {
+			t.Errorf("%s: crafted header %d: expected %v, got %v", test.name, i, errorBadData, err)
+		}
+	}
+
+	// Sizes that fit exactly still load
+	good := []struct {
+		name   string
+		filter filter
+		data   []byte
+	}{
+		{"Bloom", &Bloom{}, craft('B', 8, 64, 3, 0)},
+		{"Counting", &Counting{}, craft('C', 8, 16, 3, 0)},
+		{"Cuckoo", &Cuckoo{}, craft('K', 8, 1, 0, 0, 0)},
+	}
+
+	for _, test := range good {
+		if err := test.filter.UnmarshalBinary(test.data); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		// An empty filter holds nothing, and looking must not panic
+		if test.filter.Has([]byte("x")) {
+			t.Errorf("%s: expected x missing from an empty crafted filter", test.name)
+		}
+	}
+}
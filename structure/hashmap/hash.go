@@ -0,0 +1,46 @@
+package hashmap
+
+// Hash returns a 64 bit hash of key. Keys that are equal must hash the same,
+// and the more the low bits vary between keys the better, since the map
+// picks slots with them.
+type Hash func(key interface{}) uint64
+
+// FNV-1a constants
+const (
+	offset64 = 14695981039346656037
+	prime64  = 1099511628211
+)
+
+// Ints hashes int keys
+func Ints(key interface{}) uint64 {
+	return mix(uint64(key.(int)))
+}
+
+// Strings hashes string keys with FNV-1a
+func Strings(key interface{}) uint64 {
+	var s = key.(string)
+	var h uint64 = offset64
+
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= prime64
+	}
+
+	return mix(h)
+}
+
+// Helper Methods
+// These methods are used internally.
+
+// mix spreads every input bit over every output bit (the splitmix64
+// finalizer), so keys that differ only in their high bits still land in
+// different slots
+func mix(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+
+	return h
+}
@@ -0,0 +1,300 @@
+// Package hashmap holds implementation for an open addressing Hash Map.
+// Every entry lives in one flat slice of slots. A key goes in the slot its
+// hash points to, or the next free one after it.
+//
+// Robin Hood hashing decides who gets a slot when two keys want it: the
+// key that is further from its home slot wins, and the other moves on.
+// That keeps every key close to home, so lookups probe only a few slots
+// even at high load. Deleting shifts the keys after the hole back one
+// slot instead of leaving a tombstone, so the table never fills up with
+// dead slots.
+//
+// Unlike Go's map, iteration order is the slot order. It only depends on
+// the hash function and the keys put in, so it is the same on every run.
+package hashmap
+
+import (
+	"errors"
+	"sync"
+)
+
+// The fewest slots a map has. Must be a power of two
+const minSlots = 8
+
+// The map grows when it would be more than maxLoad eighths full, and
+// shrinks when it is less than minLoad eighths full
+const (
+	maxLoad = 7
+	minLoad = 1
+)
+
+// An error to be returned when a key is not in the map
+var errorKeyNotFound = errors.New("key not found")
+
+// slot holds an entry in the map
+type slot struct {
+	key   interface{} // Key stored here
+	value interface{} // Value held for the key
+	hash  uint64      // Hash of key, so resizing does not rehash
+	dist  uint32      // Slots from home, plus one. 0 means empty
+}
+
+// Map implements a Robin Hood Hash Map
+type Map struct {
+	mu    sync.Mutex // Mutex for safe parallel operations
+	slots []slot     // Table of entries. Length is a power of two
+	mask  uint64     // len(slots)-1, to turn a hash into a slot
+	count int        // Total number of entries
+	hash  Hash       // How we hash keys
+}
+
+// New returns a new Hash Map using hash.
+// The optional capacity may be specified, and the map starts with room
+// for that many keys. Only the first value will be used.
+func New(hash Hash, capacity ...int) *Map {
+	if hash == nil {
+		panic("nil hash provided")
+	}
+
+	var size = minSlots
+
+	if len(capacity) > 0 {
+		if capacity[0] < 0 {
+			panic("Negative value for capacity provided")
+		}
+
+		size = slotsFor(capacity[0])
+	}
+
+	var m = &Map{hash: hash}
+	m.resize(size)
+
+	return m
+}
+
+// Size returns the number of keys in the map
+func (m *Map) Size() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.count
+}
+
+// IsEmpty checks for map emptiness
+func (m *Map) IsEmpty() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.count == 0
+}
+
+// Capacity returns the number of slots in the table
+func (m *Map) Capacity() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.slots)
+}
+
+// Clear empties the map, and shrinks it back to the smallest table
+func (m *Map) Clear() {
+	m.mu.Lock()
+
+	m.slots = nil
+	m.count = 0
+	m.resize(minSlots)
+
+	m.mu.Unlock()
+}
+
+// Put sets the value for key, adding the key if it is new.
+// Keys must be comparable with ==.
+//
+// Time: O(1) expected, amortized over resizes
+func (m *Map) Put(key, value interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if (m.count+1)*8 > len(m.slots)*maxLoad {
+		m.resize(len(m.slots) * 2)
+	}
+
+	if m.insert(slot{key: key, value: value, hash: m.hash(key)}) {
+		m.count++
+	}
+}
+
+// Get returns the value for key.
+// Returns nil and error if the key is not in the map.
+//
+// Time: O(1) expected
+func (m *Map) Get(key interface{}) (interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if i, ok := m.find(key); ok {
+		return m.slots[i].value, nil
+	}
+
+	return nil, errorKeyNotFound
+}
+
+// Has returns true if key is in the map
+func (m *Map) Has(key interface{}) bool {
+	var _, err = m.Get(key)
+
+	return err == nil
+}
+
+// Delete removes key from the map.
+// Returns error if the key is not in the map.
+//
+// Time: O(1) expected, amortized over resizes
+func (m *Map) Delete(key interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var i, ok = m.find(key)
+	if !ok {
+		return errorKeyNotFound
+	}
+
+	// Shift the run after the hole back by one, until we hit an empty
+	// slot or a key already in its home slot
+	for {
+		var next = (i + 1) & m.mask
+
+		if m.slots[next].dist <= 1 {
+			break
+		}
+
+		m.slots[i] = m.slots[next]
+		m.slots[i].dist--
+		i = next
+	}
+
+	m.slots[i] = slot{}
+	m.count--
+
+	if len(m.slots) > minSlots && m.count*8 < len(m.slots)*minLoad {
+		m.resize(len(m.slots) / 2)
+	}
+
+	return nil
+}
+
+// Range calls fn for every key and value, in slot order.
+// The map is locked for the whole walk. fn must not call back into the map.
+//
+// Time: O(capacity)
+func (m *Map) Range(fn func(key, value interface{}) bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.slots {
+		if m.slots[i].dist != 0 && !fn(m.slots[i].key, m.slots[i].value) {
+			return
+		}
+	}
+}
+
+// Keys returns every key, in slot order
+func (m *Map) Keys() []interface{} {
+	var keys = make([]interface{}, 0, m.Size())
+
+	m.Range(func(key, value interface{}) bool {
+		keys = append(keys, key)
+		return true
+	})
+
+	return keys
+}
+
+// Reserve grows the table so it holds n keys without resizing again
+func (m *Map) Reserve(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if size := slotsFor(n); size > len(m.slots) {
+		m.resize(size)
+	}
+}
+
+// Helper Methods
+// These methods are used internally.
+
+// find returns the slot holding key
+func (m *Map) find(key interface{}) (uint64, bool) {
+	var hash = m.hash(key)
+	var i = hash & m.mask
+
+	for dist := uint32(1); ; dist++ {
+		var s = &m.slots[i]
+
+		// Past where key would have been put. Robin Hood never lets
+		// a key sit behind one closer to home than it
+		if s.dist < dist {
+			return 0, false
+		}
+
+		if s.hash == hash && s.key == key {
+			return i, true
+		}
+
+		i = (i + 1) & m.mask
+	}
+}
+
+// insert puts s in the table, which must have a free slot.
+// Returns false if the key was already there and only the value changed.
+func (m *Map) insert(s slot) bool {
+	var i = s.hash & m.mask
+	var carrying = false // We hold a key we took a slot from, not s
+
+	for s.dist = 1; ; s.dist++ {
+		var at = &m.slots[i]
+
+		if at.dist == 0 {
+			*at = s
+			return true
+		}
+
+		if !carrying && at.hash == s.hash && at.key == s.key {
+			at.value = s.value
+			return false
+		}
+
+		// The key here is closer to home than we are. Take its slot
+		// and carry it on instead
+		if at.dist < s.dist {
+			*at, s = s, *at
+			carrying = true
+		}
+
+		i = (i + 1) & m.mask
+	}
+}
+
+// resize moves every entry into a table of size slots
+func (m *Map) resize(size int) {
+	var old = m.slots
+
+	m.slots = make([]slot, size)
+	m.mask = uint64(size - 1)
+
+	for i := range old {
+		if old[i].dist != 0 {
+			m.insert(old[i])
+		}
+	}
+}
+
+// slotsFor returns the smallest table that holds n keys under the max load
+func slotsFor(n int) int {
+	var size = minSlots
+	for n*8 > size*maxLoad {
+		size *= 2
+	}
+
+	return size
+}
@@ -0,0 +1,285 @@
+package hashmap
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// checkMap fails the test if any key is not where its hash and distance
+// say, or if a key sits behind a run it should have displaced
+func checkMap(t *testing.T, m *Map) {
+	t.Helper()
+
+	var count int
+
+	for i, s := range m.slots {
+		if s.dist == 0 {
+			continue
+		}
+
+		count++
+
+		if home := s.hash & m.mask; (uint64(i)-home)&m.mask != uint64(s.dist-1) {
+			t.Fatalf("key %v at slot %d with distance %d, but its home is %d", s.key, i, s.dist, home)
+		}
+
+		if s.hash != m.hash(s.key) {
+			t.Fatalf("key %v has a stale hash", s.key)
+		}
+
+		// Robin Hood: a key is never more than one further from home than
+		// the key before it
+		prev := m.slots[(uint64(i)-1)&m.mask]
+		if s.dist > 1 && prev.dist+1 < s.dist {
+			t.Fatalf("key %v at distance %d follows distance %d", s.key, s.dist, prev.dist)
+		}
+	}
+
+	if count != m.count {
+		t.Fatalf("expected %d keys, counted %d", m.count, count)
+	}
+
+	if m.count*8 > len(m.slots)*maxLoad {
+		t.Fatalf("%d keys in %d slots is over the max load", m.count, len(m.slots))
+	}
+}
+
+func TestRandomized(t *testing.T) {
+	m := New(Ints)
+	expect := map[int]int{}
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 50000; i++ {
+		key := rng.Intn(5000)
+
+		// Grow for a while, then drain, to exercise both resizes
+		if rng.Intn(100) < 30+i/1000 {
+			err := m.Delete(key)
+			if _, ok := expect[key]; ok != (err == nil) {
+				t.Fatalf("Delete(%d): key present %t, got error %v", key, ok, err)
+			}
+
+			delete(expect, key)
+		} else {
+			m.Put(key, i)
+			expect[key] = i
+		}
+
+		if i%500 == 0 {
+			checkMap(t, m)
+		}
+	}
+
+	checkMap(t, m)
+
+	if m.Size() != len(expect) {
+		t.Fatalf("expected size %d, got %d", len(expect), m.Size())
+	}
+
+	for key, value := range expect {
+		if got, err := m.Get(key); err != nil || got != value {
+			t.Errorf("Get(%d): expected %d, got %v (%v)", key, value, got, err)
+		}
+	}
+
+	var seen int
+	m.Range(func(key, value interface{}) bool {
+		if expect[key.(int)] != value {
+			t.Errorf("Range: %v has %v, expected %d", key, value, expect[key.(int)])
+		}
+
+		seen++
+		return true
+	})
+
+	if seen != len(expect) {
+		t.Errorf("Range: expected %d keys, saw %d", len(expect), seen)
+	}
+}
+
+func TestResize(t *testing.T) {
+	m := New(Strings)
+
+	for i := 0; i < 1000; i++ {
+		m.Put(strconv.Itoa(i), i)
+	}
+
+	checkMap(t, m)
+	grown := m.Capacity()
+
+	if grown < 1000*8/maxLoad {
+		t.Errorf("expected room for 1000 keys, got %d slots", grown)
+	}
+
+	for i := 0; i < 990; i++ {
+		m.Delete(strconv.Itoa(i))
+	}
+
+	checkMap(t, m)
+
+	if m.Capacity() >= grown/4 {
+		t.Errorf("expected the table to shrink from %d slots, got %d", grown, m.Capacity())
+	}
+
+	m.Clear()
+	if m.Capacity() != minSlots || !m.IsEmpty() {
+		t.Errorf("expected an empty %d slot table after Clear, got %d slots", minSlots, m.Capacity())
+	}
+
+	// Reserve up front and no resize happens on the way
+	m.Reserve(5000)
+	reserved := m.Capacity()
+
+	for i := 0; i < 5000; i++ {
+		m.Put(strconv.Itoa(i), i)
+	}
+
+	if m.Capacity() != reserved {
+		t.Errorf("expected %d slots after Reserve, got %d", reserved, m.Capacity())
+	}
+
+	if New(Ints, 100).Capacity() != slotsFor(100) {
+		t.Errorf("expected New(100) to start with %d slots", slotsFor(100))
+	}
+}
+
+func TestDeterministicOrder(t *testing.T) {
+	build := func() *Map {
+		m := New(Strings)
+		for _, word := range []string{"pear", "fig", "kiwi", "apple", "plum", "date"} {
+			m.Put(word, nil)
+		}
+
+		m.Delete("kiwi")
+		return m
+	}
+
+	first := fmt.Sprint(build().Keys())
+
+	for i := 0; i < 10; i++ {
+		if got := fmt.Sprint(build().Keys()); got != first {
+			t.Fatalf("expected the same order every time, got %s and %s", first, got)
+		}
+	}
+
+	// Stopping early
+	var count int
+	build().Range(func(key, value interface{}) bool {
+		count++
+		return count < 2
+	})
+
+	if count != 2 {
+		t.Errorf("expected Range to stop after %d keys, got %d", 2, count)
+	}
+}
+
+func TestCollisions(t *testing.T) {
+	// Every key in one home slot. Still correct, just slow
+	m := New(func(key interface{}) uint64 { return 0 })
+
+	for i := 0; i < 50; i++ {
+		m.Put(i, i)
+	}
+
+	for i := 0; i < 50; i += 2 {
+		m.Delete(i)
+		checkMap(t, m)
+	}
+
+	for i := 0; i < 50; i++ {
+		if _, err := m.Get(i); (err == nil) != (i%2 == 1) {
+			t.Errorf("Get(%d): unexpected %v", i, err)
+		}
+	}
+}
+
+const benchKeys = 1 << 16
+
+func BenchmarkPut(b *testing.B) {
+	b.Run("hashmap", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			m := New(Ints)
+			for key := 0; key < benchKeys; key++ {
+				m.Put(key, key)
+			}
+		}
+	})
+
+	b.Run("map", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			m := map[interface{}]interface{}{}
+			for key := 0; key < benchKeys; key++ {
+				m[key] = key
+			}
+		}
+	})
+
+	b.Run("map+mutex", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var mu sync.Mutex
+			m := map[interface{}]interface{}{}
+			for key := 0; key < benchKeys; key++ {
+				mu.Lock()
+				m[key] = key
+				mu.Unlock()
+			}
+		}
+	})
+}
+
+func BenchmarkGet(b *testing.B) {
+	hm := New(Ints)
+	gm := map[interface{}]interface{}{}
+	var mu sync.Mutex
+
+	for key := 0; key < benchKeys; key++ {
+		hm.Put(key, key)
+		gm[key] = key
+	}
+
+	b.Run("hashmap", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			hm.Get(i % benchKeys)
+		}
+	})
+
+	b.Run("map", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = gm[i%benchKeys]
+		}
+	})
+
+	b.Run("map+mutex", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			mu.Lock()
+			_ = gm[i%benchKeys]
+			mu.Unlock()
+		}
+	})
+}
+
+func BenchmarkChurn(b *testing.B) {
+	b.Run("hashmap", func(b *testing.B) {
+		m := New(Ints)
+		for i := 0; i < b.N; i++ {
+			m.Put(i, i)
+			if i >= benchKeys {
+				m.Delete(i - benchKeys)
+			}
+		}
+	})
+
+	b.Run("map", func(b *testing.B) {
+		m := map[interface{}]interface{}{}
+		for i := 0; i < b.N; i++ {
+			m[i] = i
+			if i >= benchKeys {
+				delete(m, i-benchKeys)
+			}
+		}
+	})
+}
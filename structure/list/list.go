@@ -0,0 +1,386 @@
+// Package list holds implementation for a Doubly Linked List.
+// Each element points to the one before it and the one after it, so with
+// a handle to an element you can insert next to it, move it or remove it
+// in O(1), without walking the list to find it.
+//
+// The list is circular around a sentinel root. The root's next is the
+// front, its prev is the back, and an empty list is the root pointing at
+// itself, so no operation has to check for nil ends.
+//
+// A List is not safe for parallel use. Elements are walked through their
+// own Next and Prev, outside any lock the list could hold, so the caller
+// guards the list and its elements together with one mutex. structure/cache
+// does exactly that.
+package list
+
+import "errors"
+
+// An error to be returned when Pop-ing on an empty list
+var errorListEmpty = errors.New("empty list")
+
+// An error to be returned when an element belongs to another list, or none
+var errorNotInList = errors.New("element not in list")
+
+// An error to be returned when Splice-ing a list into itself
+var errorSameList = errors.New("cannot splice a list into itself")
+
+// Element is a handle to a value in a list
+type Element struct {
+	next  *Element // Element after us. The root when we are the back
+	prev  *Element // Element before us. The root when we are the front
+	list  *List    // List we belong to. nil once removed
+	Value interface{}
+}
+
+// Next returns the element after e, or nil if e is the back
+func (e *Element) Next() *Element {
+	if e.list == nil || e.next == &e.list.root {
+		return nil
+	}
+
+	return e.next
+}
+
+// Prev returns the element before e, or nil if e is the front
+func (e *Element) Prev() *Element {
+	if e.list == nil || e.prev == &e.list.root {
+		return nil
+	}
+
+	return e.prev
+}
+
+// List implements a Doubly Linked List
+type List struct {
+	root  Element // Sentinel. Never holds a value
+	count int     // Total number of elements minus root
+}
+
+// New returns a new Doubly Linked List holding values, front to back
+func New(values ...interface{}) *List {
+	var l = &List{}
+	l.Clear()
+
+	for _, value := range values {
+		l.PushBack(value)
+	}
+
+	return l
+}
+
+// Size returns the number of elements in the list
+func (l *List) Size() int {
+	return l.count
+}
+
+// IsEmpty checks for list emptiness
+func (l *List) IsEmpty() bool {
+	return l.count == 0
+}
+
+// Clear empties the list.
+// Elements that were in it are left detached, and Next/Prev on them return nil.
+//
+// Time: O(n)
+func (l *List) Clear() {
+	for e := l.root.next; e != nil && e != &l.root; {
+		var next = e.next
+		e.next, e.prev, e.list = nil, nil, nil
+		e = next
+	}
+
+	l.root.next = &l.root
+	l.root.prev = &l.root
+	l.count = 0
+}
+
+// Front returns the first element, or nil if the list is empty
+func (l *List) Front() *Element {
+	if l.count == 0 {
+		return nil
+	}
+
+	return l.root.next
+}
+
+// Back returns the last element, or nil if the list is empty
+func (l *List) Back() *Element {
+	if l.count == 0 {
+		return nil
+	}
+
+	return l.root.prev
+}
+
+// PushFront adds value to the front of the list, and returns its element.
+//
+// Time: O(1)
+func (l *List) PushFront(value interface{}) *Element {
+	l.lazyInit()
+
+	return l.insert(&Element{Value: value}, &l.root)
+}
+
+// PushBack adds value to the back of the list, and returns its element.
+//
+// Time: O(1)
+func (l *List) PushBack(value interface{}) *Element {
+	l.lazyInit()
+
+	return l.insert(&Element{Value: value}, l.root.prev)
+}
+
+// PopFront removes the first element and returns its value
+//
+// Time: O(1)
+func (l *List) PopFront() (interface{}, error) {
+	if l.count == 0 {
+		return nil, errorListEmpty
+	}
+
+	return l.remove(l.root.next), nil
+}
+
+// PopBack removes the last element and returns its value
+//
+// Time: O(1)
+func (l *List) PopBack() (interface{}, error) {
+	if l.count == 0 {
+		return nil, errorListEmpty
+	}
+
+	return l.remove(l.root.prev), nil
+}
+
+// InsertBefore adds value right before mark, and returns its element.
+// Returns error if mark is not in the list.
+//
+// Time: O(1)
+func (l *List) InsertBefore(value interface{}, mark *Element) (*Element, error) {
+	if mark == nil || mark.list != l {
+		return nil, errorNotInList
+	}
+
+	return l.insert(&Element{Value: value}, mark.prev), nil
+}
+
+// InsertAfter adds value right after mark, and returns its element.
+// Returns error if mark is not in the list.
+//
+// Time: O(1)
+func (l *List) InsertAfter(value interface{}, mark *Element) (*Element, error) {
+	if mark == nil || mark.list != l {
+		return nil, errorNotInList
+	}
+
+	return l.insert(&Element{Value: value}, mark), nil
+}
+
+// Remove takes e out of the list and returns its value.
+// Returns error if e is not in the list.
+//
+// Time: O(1)
+func (l *List) Remove(e *Element) (interface{}, error) {
+	if e == nil || e.list != l {
+		return nil, errorNotInList
+	}
+
+	return l.remove(e), nil
+}
+
+// MoveToFront moves e to the front of the list.
+// Returns error if e is not in the list.
+//
+// Time: O(1)
+func (l *List) MoveToFront(e *Element) error {
+	if e == nil || e.list != l {
+		return errorNotInList
+	}
+
+	l.move(e, &l.root)
+
+	return nil
+}
+
+// MoveToBack moves e to the back of the list.
+// Returns error if e is not in the list.
+//
+// Time: O(1)
+func (l *List) MoveToBack(e *Element) error {
+	if e == nil || e.list != l {
+		return errorNotInList
+	}
+
+	l.move(e, l.root.prev)
+
+	return nil
+}
+
+// MoveBefore moves e to right before mark.
+// Returns error if either is not in the list.
+//
+// Time: O(1)
+func (l *List) MoveBefore(e, mark *Element) error {
+	if e == nil || mark == nil || e.list != l || mark.list != l {
+		return errorNotInList
+	}
+
+	l.move(e, mark.prev)
+
+	return nil
+}
+
+// MoveAfter moves e to right after mark.
+// Returns error if either is not in the list.
+//
+// Time: O(1)
+func (l *List) MoveAfter(e, mark *Element) error {
+	if e == nil || mark == nil || e.list != l || mark.list != l {
+		return errorNotInList
+	}
+
+	l.move(e, mark)
+
+	return nil
+}
+
+// Splice moves every element of other, in order, to right after mark.
+// A nil mark splices them in at the front. other is left empty, and its
+// elements keep their handles, now in this list.
+// Returns error if mark is not in the list, or if other is the list.
+//
+// Time: O(m) where m is the size of other, to move the handles over
+func (l *List) Splice(mark *Element, other *List) error {
+	if other == l {
+		return errorSameList
+	}
+
+	l.lazyInit()
+
+	var at = &l.root
+	if mark != nil {
+		if mark.list != l {
+			return errorNotInList
+		}
+
+		at = mark
+	}
+
+	if other == nil || other.count == 0 {
+		return nil
+	}
+
+	var first, last = other.root.next, other.root.prev
+
+	for e := first; e != &other.root; e = e.next {
+		e.list = l
+	}
+
+	// Link the whole chain in between at and at.next
+	last.next = at.next
+	at.next.prev = last
+	at.next = first
+	first.prev = at
+
+	l.count += other.count
+
+	other.root.next = &other.root
+	other.root.prev = &other.root
+	other.count = 0
+
+	return nil
+}
+
+// Forward calls fn for each element from front to back.
+// Return false to stop early. fn may remove the element it is given.
+//
+// Time: O(n)
+func (l *List) Forward(fn func(e *Element) bool) {
+	for e := l.Front(); e != nil; {
+		var next = e.Next()
+
+		if !fn(e) {
+			return
+		}
+
+		e = next
+	}
+}
+
+// Backward calls fn for each element from back to front.
+// Return false to stop early. fn may remove the element it is given.
+//
+// Time: O(n)
+func (l *List) Backward(fn func(e *Element) bool) {
+	for e := l.Back(); e != nil; {
+		var prev = e.Prev()
+
+		if !fn(e) {
+			return
+		}
+
+		e = prev
+	}
+}
+
+// Values returns every value, front to back
+//
+// Time: O(n)
+func (l *List) Values() []interface{} {
+	var values = make([]interface{}, 0, l.count)
+
+	for e := l.Front(); e != nil; e = e.Next() {
+		values = append(values, e.Value)
+	}
+
+	return values
+}
+
+// Helper Methods
+// These methods are used internally.
+
+// lazyInit links the root to itself, for a zero value List
+func (l *List) lazyInit() {
+	if l.root.next == nil {
+		l.Clear()
+	}
+}
+
+// insert links e in after at, and returns it
+func (l *List) insert(e, at *Element) *Element {
+	e.prev = at
+	e.next = at.next
+	e.prev.next = e
+	e.next.prev = e
+	e.list = l
+
+	l.count++
+
+	return e
+}
+
+// remove unlinks e and returns its value
+func (l *List) remove(e *Element) interface{} {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.next, e.prev, e.list = nil, nil, nil
+
+	l.count--
+
+	return e.Value
+}
+
+// move unlinks e and links it back in after at
+func (l *List) move(e, at *Element) {
+	if e == at || e.prev == at {
+		return
+	}
+
+	e.prev.next = e.next
+	e.next.prev = e.prev
+
+	e.prev = at
+	e.next = at.next
+	e.prev.next = e
+	e.next.prev = e
+}
@@ -0,0 +1,238 @@
+package list
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// checkList fails the test if the links do not agree with each other, with
+// the count, or with the elements' list
+func checkList(t *testing.T, l *List) {
+	t.Helper()
+
+	var count int
+	for e := l.root.next; e != &l.root; e = e.next {
+		if e.next.prev != e || e.prev.next != e {
+			t.Fatalf("broken links around %v", e.Value)
+		}
+
+		if e.list != l {
+			t.Fatalf("element %v does not point back at its list", e.Value)
+		}
+
+		count++
+	}
+
+	if count != l.Size() {
+		t.Fatalf("expected %d elements, counted %d", l.Size(), count)
+	}
+}
+
+func values(l *List) string {
+	return fmt.Sprint(l.Values())
+}
+
+func TestOperations(t *testing.T) {
+	l := New(1, 2, 3)
+	checkList(t, l)
+
+	two := l.Front().Next()
+
+	tests := []struct {
+		name   string
+		op     func() error
+		expect string
+	}{
+		{"PushFront", func() error { l.PushFront(0); return nil }, "[0 1 2 3]"},
+		{"PushBack", func() error { l.PushBack(4); return nil }, "[0 1 2 3 4]"},
+		{"InsertBefore", func() error { _, err := l.InsertBefore(1.5, two); return err }, "[0 1 1.5 2 3 4]"},
+		{"InsertAfter", func() error { _, err := l.InsertAfter(2.5, two); return err }, "[0 1 1.5 2 2.5 3 4]"},
+		{"MoveToFront", func() error { return l.MoveToFront(two) }, "[2 0 1 1.5 2.5 3 4]"},
+		{"MoveToFront again", func() error { return l.MoveToFront(two) }, "[2 0 1 1.5 2.5 3 4]"},
+		{"MoveToBack", func() error { return l.MoveToBack(two) }, "[0 1 1.5 2.5 3 4 2]"},
+		{"MoveBefore", func() error { return l.MoveBefore(two, l.Front()) }, "[2 0 1 1.5 2.5 3 4]"},
+		{"MoveAfter", func() error { return l.MoveAfter(l.Front(), l.Back()) }, "[0 1 1.5 2.5 3 4 2]"},
+		{"Remove", func() error { _, err := l.Remove(two); return err }, "[0 1 1.5 2.5 3 4]"},
+		{"PopFront", func() error { _, err := l.PopFront(); return err }, "[1 1.5 2.5 3 4]"},
+		{"PopBack", func() error { _, err := l.PopBack(); return err }, "[1 1.5 2.5 3]"},
+	}
+
+	for _, test := range tests {
+		if err := test.op(); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		checkList(t, l)
+
+		if got := values(l); got != test.expect {
+			t.Errorf("%s: expected %s, got %s", test.name, test.expect, got)
+		}
+	}
+
+	// two was removed, so it is not in the list any more
+	if _, err := l.Remove(two); err != errorNotInList {
+		t.Errorf("Remove twice: expected %v, got %v", errorNotInList, err)
+	}
+
+	if two.Next() != nil || two.Prev() != nil {
+		t.Errorf("removed element should be detached")
+	}
+
+	if err := New(1).MoveToFront(l.Front()); err != errorNotInList {
+		t.Errorf("foreign element: expected %v, got %v", errorNotInList, err)
+	}
+
+	l.Clear()
+	checkList(t, l)
+
+	if _, err := l.PopFront(); err != errorListEmpty {
+		t.Errorf("expected %v, got %v", errorListEmpty, err)
+	}
+}
+
+func TestZeroValue(t *testing.T) {
+	var l List
+
+	if l.Front() != nil || l.Back() != nil || !l.IsEmpty() {
+		t.Errorf("zero value list should be empty")
+	}
+
+	l.PushBack(1)
+	l.PushFront(0)
+	checkList(t, &l)
+
+	if got := values(&l); got != "[0 1]" {
+		t.Errorf("expected [0 1], got %s", got)
+	}
+}
+
+func TestSplice(t *testing.T) {
+	a, b := New(1, 2, 3), New(7, 8)
+	seven := b.Front()
+
+	if err := a.Splice(a.Front(), b); err != nil {
+		t.Fatal(err)
+	}
+
+	checkList(t, a)
+	checkList(t, b)
+
+	if got := values(a); got != "[1 7 8 2 3]" {
+		t.Errorf("expected [1 7 8 2 3], got %s", got)
+	}
+
+	if !b.IsEmpty() {
+		t.Errorf("expected other list to be empty, got %s", values(b))
+	}
+
+	// Handles moved with their elements
+	if err := a.MoveToBack(seven); err != nil {
+		t.Errorf("moved handle: %v", err)
+	}
+
+	b.PushBack(0)
+	if err := a.Splice(nil, b); err != nil {
+		t.Fatal(err)
+	}
+
+	checkList(t, a)
+
+	if got := values(a); got != "[0 1 8 2 3 7]" {
+		t.Errorf("expected [0 1 8 2 3 7], got %s", got)
+	}
+
+	if err := a.Splice(nil, a); err != errorSameList {
+		t.Errorf("expected %v, got %v", errorSameList, err)
+	}
+
+	if err := a.Splice(New(1).Front(), New(2)); err != errorNotInList {
+		t.Errorf("expected %v, got %v", errorNotInList, err)
+	}
+}
+
+func TestIteration(t *testing.T) {
+	l := New(1, 2, 3, 4, 5, 6)
+
+	var forward, backward []interface{}
+	l.Forward(func(e *Element) bool {
+		forward = append(forward, e.Value)
+
+		// Removing as we go is allowed
+		if e.Value.(int)%2 == 0 {
+			l.Remove(e)
+		}
+
+		return true
+	})
+
+	l.Backward(func(e *Element) bool {
+		backward = append(backward, e.Value)
+		return len(backward) < 2
+	})
+
+	if fmt.Sprint(forward) != "[1 2 3 4 5 6]" {
+		t.Errorf("Forward: expected [1 2 3 4 5 6], got %v", forward)
+	}
+
+	if fmt.Sprint(backward) != "[5 3]" {
+		t.Errorf("Backward: expected [5 3], got %v", backward)
+	}
+
+	checkList(t, l)
+}
+
+func TestRandomized(t *testing.T) {
+	l := New()
+	var handles []*Element
+	var expect []int
+	rng := rand.New(rand.NewSource(1))
+
+	indexOf := func(e *Element) int {
+		for i, h := range handles {
+			if h == e {
+				return i
+			}
+		}
+		return -1
+	}
+
+	for i := 0; i < 5000; i++ {
+		if len(handles) == 0 || rng.Intn(4) == 0 {
+			handles = append(handles, l.PushBack(i))
+			expect = append(expect, i)
+			continue
+		}
+
+		j := rng.Intn(len(handles))
+		e := handles[j]
+
+		switch rng.Intn(3) {
+		case 0:
+			l.MoveToFront(e)
+			handles = append([]*Element{e}, append(handles[:j:j], handles[j+1:]...)...)
+			expect = append([]int{expect[j]}, append(expect[:j:j], expect[j+1:]...)...)
+		case 1:
+			l.Remove(e)
+			handles = append(handles[:j], handles[j+1:]...)
+			expect = append(expect[:j], expect[j+1:]...)
+		case 2:
+			mark := handles[rng.Intn(len(handles))]
+			if mark == e {
+				continue
+			}
+			l.MoveAfter(e, mark)
+			handles = append(handles[:j], handles[j+1:]...)
+			expect = append(expect[:j], expect[j+1:]...)
+			k := indexOf(mark) + 1
+			handles = append(handles[:k], append([]*Element{e}, handles[k:]...)...)
+			expect = append(expect[:k], append([]int{e.Value.(int)}, expect[k:]...)...)
+		}
+	}
+
+	checkList(t, l)
+
+	if got := values(l); got != fmt.Sprint(expect) {
+		t.Fatalf("list and model differ:\n%s\n%v", got, expect)
+	}
+}
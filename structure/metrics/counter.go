@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// LockWaitBounds are the upper bounds of the lock wait histogram buckets.
+// A wait longer than the last bound lands in an overflow bucket.
+var LockWaitBounds = [...]time.Duration{
+	time.Microsecond,
+	10 * time.Microsecond,
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+}
+
+// Counter is an Observer that counts events and keeps a histogram of lock
+// wait times. The zero value is ready to use.
+//
+// All fields are updated atomically so a Counter can be shared between
+// any number of containers.
+type Counter struct {
+	// int64 fields first so they are 64-bit aligned for atomic access
+
+	enqueues int64 // number of successful adds
+	dequeues int64 // number of successful removes
+	fulls    int64 // number of adds rejected on a full container
+	empties  int64 // number of removes rejected on an empty container
+	depth    int64 // last depth reported
+	maxDepth int64 // highest depth reported
+
+	waitCount int64                          // number of lock waits observed
+	waitSum   int64                          // total lock wait, in nanoseconds
+	waitHist  [len(LockWaitBounds) + 1]int64 // lock wait buckets. last is overflow
+}
+
+// Snapshot is a point in time copy of a Counter
+type Snapshot struct {
+	Enqueues int64 `json:"enqueues"`
+	Dequeues int64 `json:"dequeues"`
+	Full     int64 `json:"full"`
+	Empty    int64 `json:"empty"`
+	Depth    int64 `json:"depth"`
+	MaxDepth int64 `json:"max_depth"`
+
+	LockWaitCount int64         `json:"lock_wait_count"`
+	LockWaitTotal time.Duration `json:"lock_wait_total_ns"`
+
+	// LockWaitBuckets holds the number of waits at or under the matching
+	// entry in LockWaitBounds. The final entry counts waits over the last bound.
+	LockWaitBuckets []int64 `json:"lock_wait_buckets"`
+}
+
+// NewCounter returns a new Counter
+func NewCounter() *Counter {
+	return &Counter{}
+}
+
+// OnEnqueue counts an add and records the depth
+func (c *Counter) OnEnqueue(size int) {
+	atomic.AddInt64(&c.enqueues, 1)
+	c.setDepth(int64(size))
+}
+
+// OnDequeue counts a remove and records the depth
+func (c *Counter) OnDequeue(size int) {
+	atomic.AddInt64(&c.dequeues, 1)
+	c.setDepth(int64(size))
+}
+
+// OnFull counts a rejected add
+func (c *Counter) OnFull() {
+	atomic.AddInt64(&c.fulls, 1)
+}
+
+// OnEmpty counts a rejected remove
+func (c *Counter) OnEmpty() {
+	atomic.AddInt64(&c.empties, 1)
+}
+
+// OnLockWait adds wait to the lock wait histogram
+func (c *Counter) OnLockWait(wait time.Duration) {
+	var idx int
+
+	// Find the first bucket that holds our wait. If none do, idx ends
+	// up pointing at the overflow bucket
+	for idx = 0; idx < len(LockWaitBounds); idx++ {
+		if wait <= LockWaitBounds[idx] {
+			break
+		}
+	}
+
+	atomic.AddInt64(&c.waitHist[idx], 1)
+	atomic.AddInt64(&c.waitCount, 1)
+	atomic.AddInt64(&c.waitSum, int64(wait))
+}
+
+// Snapshot returns a copy of the current counts.
+// Each value is read atomically, but the snapshot as a whole is not.
+func (c *Counter) Snapshot() Snapshot {
+	var snap = Snapshot{
+		Enqueues:        atomic.LoadInt64(&c.enqueues),
+		Dequeues:        atomic.LoadInt64(&c.dequeues),
+		Full:            atomic.LoadInt64(&c.fulls),
+		Empty:           atomic.LoadInt64(&c.empties),
+		Depth:           atomic.LoadInt64(&c.depth),
+		MaxDepth:        atomic.LoadInt64(&c.maxDepth),
+		LockWaitCount:   atomic.LoadInt64(&c.waitCount),
+		LockWaitTotal:   time.Duration(atomic.LoadInt64(&c.waitSum)),
+		LockWaitBuckets: make([]int64, len(c.waitHist)),
+	}
+
+	for idx := range c.waitHist {
+		snap.LockWaitBuckets[idx] = atomic.LoadInt64(&c.waitHist[idx])
+	}
+
+	return snap
+}
+
+// Reset sets all counts back to zero
+func (c *Counter) Reset() {
+	atomic.StoreInt64(&c.enqueues, 0)
+	atomic.StoreInt64(&c.dequeues, 0)
+	atomic.StoreInt64(&c.fulls, 0)
+	atomic.StoreInt64(&c.empties, 0)
+	atomic.StoreInt64(&c.depth, 0)
+	atomic.StoreInt64(&c.maxDepth, 0)
+	atomic.StoreInt64(&c.waitCount, 0)
+	atomic.StoreInt64(&c.waitSum, 0)
+
+	for idx := range c.waitHist {
+		atomic.StoreInt64(&c.waitHist[idx], 0)
+	}
+}
+
+// setDepth stores the current depth and raises the max depth if needed
+func (c *Counter) setDepth(depth int64) {
+	atomic.StoreInt64(&c.depth, depth)
+
+	// Compare and swap until either we stored our depth, or someone else
+	// stored a bigger one
+	for {
+		var top = atomic.LoadInt64(&c.maxDepth)
+
+		if depth <= top || atomic.CompareAndSwapInt64(&c.maxDepth, top, depth) {
+			return
+		}
+	}
+}
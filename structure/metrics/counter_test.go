@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+	"time"
+)
+
+func TestCounter(t *testing.T) {
+	counter := NewCounter()
+	counter.OnEnqueue(1)
+	counter.OnEnqueue(2)
+	counter.OnEnqueue(3)
+	counter.OnDequeue(2)
+	counter.OnFull()
+	counter.OnEmpty()
+	counter.OnEmpty()
+	counter.OnLockWait(500 * time.Nanosecond)
+	counter.OnLockWait(2 * time.Millisecond)
+	counter.OnLockWait(time.Minute)
+
+	snap := counter.Snapshot()
+
+	if snap.Enqueues != 3 {
+		t.Errorf("expected %d enqueues, got %d", 3, snap.Enqueues)
+	}
+
+	if snap.Dequeues != 1 {
+		t.Errorf("expected %d dequeues, got %d", 1, snap.Dequeues)
+	}
+
+	if snap.Full != 1 || snap.Empty != 2 {
+		t.Errorf("expected full %d empty %d, got %d %d", 1, 2, snap.Full, snap.Empty)
+	}
+
+	if snap.Depth != 2 || snap.MaxDepth != 3 {
+		t.Errorf("expected depth %d max %d, got %d %d", 2, 3, snap.Depth, snap.MaxDepth)
+	}
+
+	if snap.LockWaitCount != 3 {
+		t.Errorf("expected %d lock waits, got %d", 3, snap.LockWaitCount)
+	}
+
+	expect := []int64{1, 0, 0, 0, 1, 0, 0, 1}
+	for idx, value := range expect {
+		if snap.LockWaitBuckets[idx] != value {
+			t.Errorf("bucket %d: expected %d, got %d", idx, value, snap.LockWaitBuckets[idx])
+		}
+	}
+
+	counter.Reset()
+
+	if snap = counter.Snapshot(); snap.Enqueues != 0 || snap.LockWaitCount != 0 {
+		t.Errorf("expected reset counter, got %+v", snap)
+	}
+}
+
+func TestPublish(t *testing.T) {
+	counter := NewCounter()
+	counter.OnEnqueue(7)
+
+	Publish("metrics_test_counter", counter)
+
+	var snap Snapshot
+	if err := json.Unmarshal([]byte(expvar.Get("metrics_test_counter").String()), &snap); err != nil {
+		t.Fatal(err)
+	}
+
+	if snap.Enqueues != 1 || snap.Depth != 7 {
+		t.Errorf("expected enqueues %d depth %d, got %d %d", 1, 7, snap.Enqueues, snap.Depth)
+	}
+}
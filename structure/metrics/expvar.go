@@ -0,0 +1,13 @@
+package metrics
+
+import "expvar"
+
+// Publish exports c under name with the expvar package.
+// The exported value is a Snapshot, taken each time the variable is read.
+//
+// Like expvar.Publish, Publish panics if name is already in use.
+func Publish(name string, c *Counter) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return c.Snapshot()
+	}))
+}
@@ -0,0 +1,50 @@
+package metrics
+
+import "time"
+
+// multi passes every event on to a list of observers
+type multi []Observer
+
+// Multi returns an Observer that passes every event to each of observers,
+// in order. nil observers are skipped.
+func Multi(observers ...Observer) Observer {
+	var list = make(multi, 0, len(observers))
+
+	for _, o := range observers {
+		if o != nil {
+			list = append(list, o)
+		}
+	}
+
+	return list
+}
+
+func (m multi) OnEnqueue(size int) {
+	for _, o := range m {
+		o.OnEnqueue(size)
+	}
+}
+
+func (m multi) OnDequeue(size int) {
+	for _, o := range m {
+		o.OnDequeue(size)
+	}
+}
+
+func (m multi) OnFull() {
+	for _, o := range m {
+		o.OnFull()
+	}
+}
+
+func (m multi) OnEmpty() {
+	for _, o := range m {
+		o.OnEmpty()
+	}
+}
+
+func (m multi) OnLockWait(wait time.Duration) {
+	for _, o := range m {
+		o.OnLockWait(wait)
+	}
+}
@@ -0,0 +1,52 @@
+// Package metrics holds instrumentation hooks for the structures.
+// A container with an Observer attached reports every change in depth,
+// every rejected operation and the time spent waiting on its mutex.
+// A container without an Observer pays nothing more than a nil check.
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// Observer receives events from a container.
+// Stacks report Push as an enqueue and Pop as a dequeue.
+//
+// Methods are called after the container mutex has been released, so an
+// Observer may call back into the container. Implementations must be safe
+// for concurrent use.
+type Observer interface {
+	// OnEnqueue is called after a value has been added.
+	// size is the number of items in the container after the add.
+	OnEnqueue(size int)
+
+	// OnDequeue is called after a value has been removed.
+	// size is the number of items in the container after the remove.
+	OnDequeue(size int)
+
+	// OnFull is called when an add was rejected because the container is full
+	OnFull()
+
+	// OnEmpty is called when a remove was rejected because the container is empty
+	OnEmpty()
+
+	// OnLockWait is called with the time spent waiting to acquire the
+	// container mutex
+	OnLockWait(wait time.Duration)
+}
+
+// Lock locks mu, reporting the time spent waiting to o.
+// If o is nil, mu is locked without reading the clock.
+func Lock(mu *sync.Mutex, o Observer) {
+	// No observer, no clock
+	if o == nil {
+		mu.Lock()
+		return
+	}
+
+	var start = time.Now()
+
+	mu.Lock()
+
+	o.OnLockWait(time.Since(start))
+}
@@ -6,6 +6,8 @@ package channel
 import (
 	"errors"
 	"sync"
+
+	"github.com/noriah/go-code/structure/metrics"
 )
 
 // An error to be returned when Push-ing on a full queue
@@ -28,6 +30,8 @@ type Queue struct {
 	size int
 	// Capacity of the channel. Never changes
 	capacity int
+	// Optional observer. nil means no instrumentation
+	observer metrics.Observer
 }
 
 // New returns a new Channel Queue
@@ -48,7 +52,7 @@ func New(capacity int) *Queue {
 // Size returns the current number of items in the queue
 func (q *Queue) Size() int {
 	// Lock the mutex so we can get the size at time of the queue
-	q.mu.Lock()
+	metrics.Lock(&q.mu, q.observer)
 	// Defer the unlock to after we return
 	defer q.mu.Unlock()
 
@@ -70,7 +74,7 @@ func (q *Queue) IsEmpty() bool {
 // Clear removes all items from the queue
 func (q *Queue) Clear() {
 	// Lock the mutex so we can empty the channel in peace
-	q.mu.Lock()
+	metrics.Lock(&q.mu, q.observer)
 	// Defer the unlock to after the func exits
 	defer q.mu.Unlock()
 
@@ -92,13 +96,19 @@ func (q *Queue) Clear() {
 	}
 }
 
+// SetObserver attaches an observer to the queue. Passing nil detaches it.
+// The observer should be set before the queue is shared between goroutines.
+func (q *Queue) SetObserver(o metrics.Observer) {
+	q.mu.Lock()
+	q.observer = o
+	q.mu.Unlock()
+}
+
 // Push adds a value to the internal channel.
 // Returns error if queue is full
 func (q *Queue) Push(value interface{}) error {
 	// Lock the mutex so we can push in peace
-	q.mu.Lock()
-	// Defer the unlock to after the func exits
-	defer q.mu.Unlock()
+	metrics.Lock(&q.mu, q.observer)
 
 	// Select an action
 	select {
@@ -106,10 +116,24 @@ func (q *Queue) Push(value interface{}) error {
 	case q.channel <- value:
 		// We sent! increment the size
 		q.size++
+		// Hold on to the size so we can report it outside the lock
+		var size = q.size
+		// Unlock the mutex
+		q.mu.Unlock()
+
+		if q.observer != nil {
+			q.observer.OnEnqueue(size)
+		}
 		// return no error
 		return nil
 		// Unable to send on channel
 	default:
+		// Unlock the mutex
+		q.mu.Unlock()
+
+		if q.observer != nil {
+			q.observer.OnFull()
+		}
 		// Return queue full error
 		return errorQueueFull
 	}
@@ -119,9 +143,7 @@ func (q *Queue) Push(value interface{}) error {
 // Returns nil and error if queue is empty.
 func (q *Queue) Pop() (interface{}, error) {
 	// Lock the mutex so we can pop in peace
-	q.mu.Lock()
-	// Defer the unlock to after the func exits
-	defer q.mu.Unlock()
+	metrics.Lock(&q.mu, q.observer)
 
 	// Define some variables for later.
 	var value interface{}
@@ -137,15 +159,32 @@ func (q *Queue) Pop() (interface{}, error) {
 		if ok {
 			// Decrement the size
 			q.size--
+			// Hold on to the size so we can report it outside the lock
+			var size = q.size
+			// Unlock the mutex
+			q.mu.Unlock()
+
+			if q.observer != nil {
+				q.observer.OnDequeue(size)
+			}
 			// return the value and no error
 			return value, nil
 		}
 		// If we can't pull from the channel
 	default:
+		// Unlock the mutex
+		q.mu.Unlock()
+
+		if q.observer != nil {
+			q.observer.OnEmpty()
+		}
 		// Return nil and Queue empty error
 		return nil, errorQueueEmpty
 	}
 
+	// Unlock the mutex
+	q.mu.Unlock()
+
 	// How did we get here?
 	// Return whatever we got and channel closed error
 	return value, errorChannelClosed
@@ -0,0 +1,36 @@
+package channel
+
+import (
+	"testing"
+
+	"github.com/noriah/go-code/structure/metrics"
+)
+
+func TestChannelQueueObserver(t *testing.T) {
+	counter := metrics.NewCounter()
+
+	q := New(3)
+	q.SetObserver(counter)
+	q.Push(1)
+	q.Push(2)
+	q.Push(3)
+	q.Push(4)
+	q.Pop()
+	q.Clear()
+	q.Pop()
+
+	snap := counter.Snapshot()
+
+	if snap.Enqueues != 3 || snap.Dequeues != 1 || snap.Full != 1 || snap.Empty != 1 {
+		t.Errorf("expected 3/1/1/1 enqueues/dequeues/full/empty, got %d/%d/%d/%d",
+			snap.Enqueues, snap.Dequeues, snap.Full, snap.Empty)
+	}
+
+	if snap.MaxDepth != 3 {
+		t.Errorf("expected max depth %d, got %d", 3, snap.MaxDepth)
+	}
+
+	if snap.LockWaitCount == 0 {
+		t.Error("expected lock waits to be reported")
+	}
+}
@@ -7,6 +7,8 @@ package linked
 import (
 	"errors"
 	"sync"
+
+	"github.com/noriah/go-code/structure/metrics"
 )
 
 // An error to be returned when Push-ing on a full queue
@@ -32,6 +34,8 @@ type Queue struct {
 	tail     *Node      // Tail node of our queue. Real node unless empty, then root
 	count    int        // Total number of nodes minus root node
 	capacity int        // Maximum size of our queue. 0 means no limit (dynamic)
+
+	observer metrics.Observer // Optional observer. nil means no instrumentation
 }
 
 // New returns a new Linked List Queue.
@@ -67,7 +71,7 @@ func New(size ...int) *Queue {
 func (q *Queue) Size() int {
 
 	// Lock the mutex so we don't check in the middle of an operation
-	q.mu.Lock()
+	metrics.Lock(&q.mu, q.observer)
 
 	// To avoid assigning a temp variable just for the count, we can defer
 	// the mutex unlock to after we have returned
@@ -77,6 +81,13 @@ func (q *Queue) Size() int {
 	return q.count
 }
 
+// Capacity returns the maximum number of items in the queue.
+// 0 means there is no limit.
+func (q *Queue) Capacity() int {
+	// Return the capacity
+	return q.capacity
+}
+
 // IsEmpty checks for queue emptiness
 func (q *Queue) IsEmpty() bool {
 
@@ -98,7 +109,7 @@ func (q *Queue) Clear() {
 
 	// Lock our mutex so we can be sure to clear the queue before any other
 	// operations happen on it
-	q.mu.Lock()
+	metrics.Lock(&q.mu, q.observer)
 
 	// Do our clear things
 	q.clear()
@@ -123,6 +134,11 @@ func (q *Queue) Clear() {
 func (q *Queue) Enqueue(value interface{}) error {
 	// Fullness check
 	if q.IsFull() {
+		// Let the observer know we turned someone away
+		if q.observer != nil {
+			q.observer.OnFull()
+		}
+
 		// Return error on full
 		return errorQueueFull
 	}
@@ -140,7 +156,7 @@ func (q *Queue) Enqueue(value interface{}) error {
 
 	// Lock the mutex while we are modifying the queue. Prevents someone
 	// Adding a node before we do, and having a messed up queue
-	q.mu.Lock()
+	metrics.Lock(&q.mu, q.observer)
 
 	// Set the next node value at the tail of our queue to be our new node
 	q.tail.next = newNode
@@ -151,9 +167,16 @@ func (q *Queue) Enqueue(value interface{}) error {
 	// Increment the total items in queue
 	q.count++
 
+	// Hold on to the count so we can report it outside the lock
+	var size = q.count
+
 	// Unlock the mutex
 	q.mu.Unlock()
 
+	if q.observer != nil {
+		q.observer.OnEnqueue(size)
+	}
+
 	return nil
 }
 
@@ -222,7 +245,7 @@ func (q *Queue) Append(values ...interface{}) {
 	// Lock the mutex so we can be sure to add our new mini-queue to the
 	// end of the actual queue. Without this, we could be in a race condition
 	// where we took long enough to build the mini-queue that another
-	q.mu.Lock()
+	metrics.Lock(&q.mu, q.observer)
 
 	// Set next on the tail queue item to point to our mini-queue start
 	q.tail.next = next
@@ -233,8 +256,18 @@ func (q *Queue) Append(values ...interface{}) {
 	// increase our count by number of values
 	q.count += vLen
 
+	// Hold on to the count so we can report it outside the lock
+	var size = q.count
+
 	// Unlock the mutex
 	q.mu.Unlock()
+
+	// Report each value we added, in order
+	if q.observer != nil {
+		for idx = size - vLen + 1; idx <= size; idx++ {
+			q.observer.OnEnqueue(idx)
+		}
+	}
 }
 
 // Dequeue returns the value at the front of the queue, removing it from the queue
@@ -245,6 +278,11 @@ func (q *Queue) Dequeue() (interface{}, error) {
 	// If our tail node is the same our our root node, then we have an empty queue
 	if q.tail == q.root {
 
+		// Let the observer know there was nothing to hand out
+		if q.observer != nil {
+			q.observer.OnEmpty()
+		}
+
 		// Return a nil value and our error
 		return nil, errorQueueEmpty
 	}
@@ -254,7 +292,7 @@ func (q *Queue) Dequeue() (interface{}, error) {
 
 	// Lock the mutex so nobody can modify the queue while we are removing
 	// the head of the queue
-	q.mu.Lock()
+	metrics.Lock(&q.mu, q.observer)
 
 	// assign the current head node to our variable so we don't lose it
 	temp = q.root.next
@@ -262,12 +300,25 @@ func (q *Queue) Dequeue() (interface{}, error) {
 	// set the queue to point to the next item still in queue
 	q.root.next = temp.next
 
+	// If we just took the last node, point the tail back at our root so
+	// the queue reads as empty again
+	if temp == q.tail {
+		q.tail = q.root
+	}
+
 	// decrement our count of items in queue
 	q.count--
 
+	// Hold on to the count so we can report it outside the lock
+	var size = q.count
+
 	// Unlock the mutex
 	q.mu.Unlock()
 
+	if q.observer != nil {
+		q.observer.OnDequeue(size)
+	}
+
 	// return the value in our temp node
 	return temp.value, nil
 }
@@ -284,7 +335,7 @@ func (q *Queue) Peek() (interface{}, error) {
 	}
 
 	// Lock the internal mutex to prevent someone pop-ing while we are peek-ing
-	q.mu.Lock()
+	metrics.Lock(&q.mu, q.observer)
 
 	// Unlock the mutex
 	defer q.mu.Unlock()
@@ -293,6 +344,24 @@ func (q *Queue) Peek() (interface{}, error) {
 	return q.root.next.value, nil
 }
 
+// Push is Enqueue. It lets a linked queue be used as a queue.Queue
+func (q *Queue) Push(value interface{}) error {
+	return q.Enqueue(value)
+}
+
+// Pop is Dequeue. It lets a linked queue be used as a queue.Queue
+func (q *Queue) Pop() (interface{}, error) {
+	return q.Dequeue()
+}
+
+// SetObserver attaches an observer to the queue. Passing nil detaches it.
+// The observer should be set before the queue is shared between goroutines.
+func (q *Queue) SetObserver(o metrics.Observer) {
+	q.mu.Lock()
+	q.observer = o
+	q.mu.Unlock()
+}
+
 // Helper Methods
 // These methods are used internally.
 
@@ -1,5 +1,11 @@
 package linked
 
+import (
+	"testing"
+
+	"github.com/noriah/go-code/structure/metrics"
+)
+
 func generateIntArray(size int) []int {
 	var ret = make([]int, size)
 	for i := 0; i < size; i++ {
@@ -8,4 +14,66 @@ func generateIntArray(size int) []int {
 	return ret
 }
 
-// TODO: Write tests!
+// Taking the last node must point the tail back at the root, or the queue
+// never reads as empty again and the next Enqueue hangs off a dead node
+func TestDequeueLast(t *testing.T) {
+	q := New()
+
+	for round := 0; round < 3; round++ {
+		q.Enqueue(round)
+
+		if value, err := q.Dequeue(); err != nil || value != round {
+			t.Fatalf("round %d: expected %d, got %v (%v)", round, round, value, err)
+		}
+
+		if !q.IsEmpty() || q.Size() != 0 {
+			t.Fatalf("round %d: expected empty queue after taking the last value", round)
+		}
+
+		if _, err := q.Dequeue(); err == nil {
+			t.Fatalf("round %d: expected error dequeuing from an empty queue", round)
+		}
+	}
+
+	// Same again after a batch from Append
+	q.Append(1, 2)
+	q.Dequeue()
+	q.Dequeue()
+
+	if !q.IsEmpty() {
+		t.Fatal("expected empty queue after taking an appended batch")
+	}
+
+	q.Enqueue(3)
+	if value, _ := q.Peek(); value != 3 {
+		t.Fatalf("expected 3 at the front, got %v", value)
+	}
+}
+
+func TestLinkedQueueObserver(t *testing.T) {
+	counter := metrics.NewCounter()
+
+	q := New(3)
+	q.SetObserver(counter)
+	q.Append(1, 2)
+	q.Enqueue(3)
+	q.Enqueue(4)
+	q.Dequeue()
+	q.Clear()
+	q.Dequeue()
+
+	snap := counter.Snapshot()
+
+	if snap.Enqueues != 3 || snap.Dequeues != 1 || snap.Full != 1 || snap.Empty != 1 {
+		t.Errorf("expected 3/1/1/1 enqueues/dequeues/full/empty, got %d/%d/%d/%d",
+			snap.Enqueues, snap.Dequeues, snap.Full, snap.Empty)
+	}
+
+	if snap.MaxDepth != 3 {
+		t.Errorf("expected max depth %d, got %d", 3, snap.MaxDepth)
+	}
+
+	if snap.LockWaitCount == 0 {
+		t.Error("expected lock waits to be reported")
+	}
+}
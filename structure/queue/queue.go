@@ -0,0 +1,42 @@
+// Package queue holds the surface shared by the queue implementations.
+// Anything that wraps or consumes a queue should take a Queue, so that any
+// of linked, slice or channel can be plugged in.
+package queue
+
+// Queue is a first in, first out collection.
+//
+// Push returns an error if the value could not be added (a bounded queue
+// is full). Pop returns an error if there is nothing to remove.
+type Queue interface {
+	Push(value interface{}) error
+	Pop() (interface{}, error)
+	Size() int
+	IsEmpty() bool
+	Clear()
+}
+
+// Unbounded is a queue whose Push cannot fail, like slice.Queue
+type Unbounded interface {
+	Push(value interface{})
+	Pop() (interface{}, error)
+	Size() int
+	IsEmpty() bool
+	Clear()
+}
+
+// FromUnbounded returns q as a Queue. Push always returns nil
+func FromUnbounded(q Unbounded) Queue {
+	return unbounded{q}
+}
+
+// unbounded adds the error result to an Unbounded Push
+type unbounded struct {
+	Unbounded
+}
+
+// Push adds value to the queue
+func (u unbounded) Push(value interface{}) error {
+	u.Unbounded.Push(value)
+
+	return nil
+}
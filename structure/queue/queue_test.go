@@ -0,0 +1,50 @@
+package queue
+
+import (
+	"testing"
+
+	"github.com/noriah/go-code/structure/queue/channel"
+	"github.com/noriah/go-code/structure/queue/linked"
+	"github.com/noriah/go-code/structure/queue/slice"
+)
+
+func TestQueues(t *testing.T) {
+	queues := map[string]Queue{
+		"linked":  linked.New(),
+		"slice":   FromUnbounded(slice.New()),
+		"channel": channel.New(64),
+	}
+
+	for name, queue := range queues {
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < 40; i++ {
+				if err := queue.Push(i); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if size := queue.Size(); size != 40 {
+				t.Errorf("expected size %d, got %d", 40, size)
+			}
+
+			for i := 0; i < 40; i++ {
+				value, err := queue.Pop()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if value.(int) != i {
+					t.Errorf("expected %d, got %d", i, value)
+				}
+			}
+
+			if !queue.IsEmpty() {
+				t.Error("expected empty queue")
+			}
+
+			if _, err := queue.Pop(); err == nil {
+				t.Error("expected error on empty pop")
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package ratelimit
+
+import "time"
+
+// Clock is the source of time for a rate limited queue.
+// Tests can swap in a fake clock to step time by hand.
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+
+	// After returns a channel that receives once d has passed
+	After(d time.Duration) <-chan time.Time
+}
+
+// systemClock is a Clock backed by the time package
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// SystemClock is the Clock used when none is given
+var SystemClock Clock = systemClock{}
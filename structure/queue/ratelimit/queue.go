@@ -0,0 +1,157 @@
+// Package ratelimit implements a rate limited queue.
+// A rate limited queue wraps any queue.Queue and gates Pop through a token
+// bucket. The bucket holds up to burst tokens and refills at rate tokens
+// per second. Each value popped costs one token.
+//
+// Push and the other methods pass straight through to the wrapped queue.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/noriah/go-code/structure/queue"
+)
+
+// An error to be returned when Pop-ing with no tokens left
+var errorRateLimited = errors.New("rate limited")
+
+// Queue is a queue.Queue with a token bucket in front of Pop
+type Queue struct {
+	queue.Queue // The wrapped queue
+
+	mu     sync.Mutex // Mutex for safe parallel operations
+	clock  Clock      // Where we get our time from
+	rate   float64    // Tokens added per second
+	burst  float64    // Maximum tokens in the bucket
+	tokens float64    // Tokens in the bucket as of last refill
+	last   time.Time  // Last time we refilled the bucket
+}
+
+// New returns a new rate limited Queue wrapping q.
+// rate is in tokens per second and must be positive. burst must be at least 1.
+// The bucket starts full.
+// The optional clock may be specified. Only the first value will be used.
+func New(q queue.Queue, rate float64, burst int, clock ...Clock) *Queue {
+	if rate <= 0 {
+		panic("Non-positive value for rate provided")
+	}
+
+	if burst < 1 {
+		panic("Value less than 1 for burst provided")
+	}
+
+	// Use the real clock unless we were given one
+	var c = SystemClock
+	if len(clock) > 0 && clock[0] != nil {
+		c = clock[0]
+	}
+
+	return &Queue{
+		Queue:  q,
+		clock:  c,
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   c.Now(),
+	}
+}
+
+// Tokens returns the number of whole tokens in the bucket right now
+func (l *Queue) Tokens() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill()
+
+	return int(l.tokens)
+}
+
+// Pop removes a value from the wrapped queue if a token is available.
+// Returns error without waiting if the bucket is empty.
+// A token is only spent if a value was removed.
+func (l *Queue) Pop() (interface{}, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill()
+
+	// No token, no value
+	if l.tokens < 1 {
+		return nil, errorRateLimited
+	}
+
+	return l.pop()
+}
+
+// PopWait removes a value from the wrapped queue, waiting for a token if
+// needed. It stops waiting and returns the context error if ctx is done.
+//
+// PopWait only waits on the bucket. If the wrapped queue is empty once a
+// token is available, its error is returned straight away.
+func (l *Queue) PopWait(ctx context.Context) (interface{}, error) {
+	for {
+		l.mu.Lock()
+
+		l.refill()
+
+		// Got a token. take our value
+		if l.tokens >= 1 {
+			value, err := l.pop()
+			l.mu.Unlock()
+			return value, err
+		}
+
+		// Work out how long until the next whole token
+		var wait = time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+
+		case <-l.clock.After(wait):
+			// Go around and try again. Someone else may have beaten us to it
+		}
+	}
+}
+
+// Helper Methods
+// These methods are used internally.
+
+// pop takes a value from the wrapped queue and spends a token on success.
+// The mutex must be held.
+func (l *Queue) pop() (interface{}, error) {
+	value, err := l.Queue.Pop()
+	if err != nil {
+		return nil, err
+	}
+
+	l.tokens--
+
+	return value, nil
+}
+
+// refill adds tokens earned since the last refill, up to burst.
+// The mutex must be held.
+func (l *Queue) refill() {
+	var now = l.clock.Now()
+
+	var elapsed = now.Sub(l.last)
+
+	// Clocks can go backwards. Don't take tokens away
+	if elapsed <= 0 {
+		return
+	}
+
+	l.last = now
+
+	l.tokens += elapsed.Seconds() * l.rate
+
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
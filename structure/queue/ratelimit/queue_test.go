@@ -0,0 +1,164 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/noriah/go-code/structure/queue/linked"
+)
+
+// fakeClock only moves when told to
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(0, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, fakeWaiter{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance moves the clock forward and fires any waiters that are due
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+
+	waiters := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			waiters = append(waiters, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = waiters
+}
+
+// Waiting returns the number of pending After calls
+func (c *fakeClock) Waiting() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+func fill(size int) *linked.Queue {
+	queue := linked.New()
+	for i := 0; i < size; i++ {
+		queue.Enqueue(i)
+	}
+	return queue
+}
+
+func TestRateLimitPop(t *testing.T) {
+	clock := newFakeClock()
+	limited := New(fill(10), 2, 3, clock)
+
+	// Burst of 3 goes straight through
+	for i := 0; i < 3; i++ {
+		if _, err := limited.Pop(); err != nil {
+			t.Fatalf("pop %d: %v", i, err)
+		}
+	}
+
+	if _, err := limited.Pop(); err != errorRateLimited {
+		t.Fatalf("expected %v, got %v", errorRateLimited, err)
+	}
+
+	// 2 per second. half a second buys one token
+	clock.Advance(500 * time.Millisecond)
+
+	if value, err := limited.Pop(); err != nil || value.(int) != 3 {
+		t.Fatalf("expected %d, got %v (%v)", 3, value, err)
+	}
+
+	// A long idle period never gives more than burst
+	clock.Advance(time.Hour)
+
+	if tokens := limited.Tokens(); tokens != 3 {
+		t.Errorf("expected %d tokens, got %d", 3, tokens)
+	}
+}
+
+func TestRateLimitEmptyKeepsToken(t *testing.T) {
+	clock := newFakeClock()
+	limited := New(linked.New(), 1, 1, clock)
+
+	if _, err := limited.Pop(); err == nil || err == errorRateLimited {
+		t.Fatalf("expected empty queue error, got %v", err)
+	}
+
+	if tokens := limited.Tokens(); tokens != 1 {
+		t.Errorf("expected %d token, got %d", 1, tokens)
+	}
+}
+
+func TestRateLimitPopWait(t *testing.T) {
+	clock := newFakeClock()
+	limited := New(fill(10), 4, 1, clock)
+
+	limited.Pop()
+
+	done := make(chan interface{})
+	go func() {
+		value, err := limited.PopWait(context.Background())
+		if err != nil {
+			t.Error(err)
+		}
+		done <- value
+	}()
+
+	// Wait for the popper to start sleeping on the clock
+	for clock.Waiting() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	select {
+	case <-done:
+		t.Fatal("PopWait returned before a token was available")
+	default:
+	}
+
+	clock.Advance(250 * time.Millisecond)
+
+	if value := <-done; value.(int) != 1 {
+		t.Errorf("expected %d, got %v", 1, value)
+	}
+}
+
+func TestRateLimitPopWaitCancel(t *testing.T) {
+	clock := newFakeClock()
+	limited := New(fill(10), 1, 1, clock)
+
+	limited.Pop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := limited.PopWait(ctx); err != context.Canceled {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+}
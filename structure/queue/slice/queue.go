@@ -5,6 +5,8 @@ package slice
 import (
 	"errors"
 	"sync"
+
+	"github.com/noriah/go-code/structure/metrics"
 )
 
 const defaultSliceSize = 16
@@ -19,13 +21,14 @@ var errorChannelClosed = errors.New("closed channel")
 // It uses channels internally to keep track of open slots in the array
 // so that we never have to shift, nor do we waste space
 type Queue struct {
-	mu         sync.Mutex    // Mutex to lock when we are modifying things
-	array      []interface{} // array to hold the data
-	deqChannel chan int      // Channel to hold our indexes for Dequeue
-	enqChannel chan int      // channel to hold our indexes for Enqueue
-	size       int           // Size to keep track how many items are in the array
-	nextPop    int           // the next index to pop
-	nextPush   int           // the next index at the end of the array we can push to
+	mu         sync.Mutex       // Mutex to lock when we are modifying things
+	array      []interface{}    // array to hold the data
+	deqChannel chan int         // Channel to hold our indexes for Dequeue
+	enqChannel chan int         // channel to hold our indexes for Enqueue
+	size       int              // Size to keep track how many items are in the array
+	nextPop    int              // the next index to pop
+	nextPush   int              // the next index at the end of the array we can push to
+	observer   metrics.Observer // Optional observer. nil means no instrumentation
 }
 
 // New returns a new Slice Queue
@@ -59,8 +62,11 @@ func (q *Queue) expand() {
 	for {
 		// Select between options. Pick the first available
 		select {
-		case newPopChannel <- q.deqChannel:
-		case newPushChannel <- q.enqChannel:
+		// Move the indexes over in the order we received them
+		case idx := <-q.deqChannel:
+			newPopChannel <- idx
+		case idx := <-q.enqChannel:
+			newPushChannel <- idx
 		default:
 			close(q.deqChannel)
 			q.deqChannel = newPopChannel
@@ -74,7 +80,7 @@ func (q *Queue) expand() {
 // Size returns the current number of items in the queue
 func (q *Queue) Size() int {
 	// Lock the mutex so we can get the size at time of the queue
-	q.mu.Lock()
+	metrics.Lock(&q.mu, q.observer)
 	// Defer the unlock to after we return
 	defer q.mu.Unlock()
 
@@ -85,7 +91,7 @@ func (q *Queue) Size() int {
 // Clear removes all items from the queue
 func (q *Queue) Clear() {
 	// Lock the mutex so we can empty the channels in peace
-	q.mu.Lock()
+	metrics.Lock(&q.mu, q.observer)
 
 	defer q.mu.Unlock()
 
@@ -115,13 +121,21 @@ func (q *Queue) IsEmpty() bool {
 	return q.Size() == 0
 }
 
+// SetObserver attaches an observer to the queue. Passing nil detaches it.
+// The observer should be set before the queue is shared between goroutines.
+func (q *Queue) SetObserver(o metrics.Observer) {
+	q.mu.Lock()
+	q.observer = o
+	q.mu.Unlock()
+}
+
 // Push adds a value to the internal array.
 func (q *Queue) Push(value interface{}) {
 	var idx int
 	var ok bool
 
 	// Lock the mutex so we can push in peace
-	q.mu.Lock()
+	metrics.Lock(&q.mu, q.observer)
 
 	if q.size >= cap(q.array) {
 		q.expand()
@@ -150,7 +164,14 @@ func (q *Queue) Push(value interface{}) {
 		panic("What??? full pop channel?")
 	}
 
+	// Hold on to the size so we can report it outside the lock
+	var size = q.size
+
 	q.mu.Unlock()
+
+	if q.observer != nil {
+		q.observer.OnEnqueue(size)
+	}
 }
 
 // Pop removes a value from the internal channel and returns the value
@@ -158,13 +179,15 @@ func (q *Queue) Push(value interface{}) {
 // Returns nil and error if queue is empty.
 func (q *Queue) Pop() (interface{}, error) {
 	if q.size == 0 {
+		if q.observer != nil {
+			q.observer.OnEmpty()
+		}
+
 		return nil, errorQueueEmpty
 	}
 
 	// Lock the mutex so we can pop in peace
-	q.mu.Lock()
-	// Defer the unlock to after the func exits
-	defer q.mu.Unlock()
+	metrics.Lock(&q.mu, q.observer)
 
 	var idx = q.nextPop
 
@@ -182,6 +205,12 @@ func (q *Queue) Pop() (interface{}, error) {
 				panic("What happened here with push channel??")
 			}
 		default:
+			q.mu.Unlock()
+
+			if q.observer != nil {
+				q.observer.OnEmpty()
+			}
+
 			return nil, errorQueueEmpty
 		}
 	}
@@ -191,12 +220,20 @@ func (q *Queue) Pop() (interface{}, error) {
 		q.size--
 
 	default:
+		q.mu.Unlock()
 		panic("What??? full push channel?")
 	}
 
-	// How did we get here?
-	// Return whatever we got and channel closed error
-	return q.array[idx], nil
+	// Grab the value and size before we let go of the lock
+	var value, size = q.array[idx], q.size
+
+	q.mu.Unlock()
+
+	if q.observer != nil {
+		q.observer.OnDequeue(size)
+	}
+
+	return value, nil
 }
 
 // Peek returns the value at the front of the queue.
@@ -207,7 +244,7 @@ func (q *Queue) Peek() (interface{}, error) {
 	}
 
 	// Lock the mutex so we can pop in peace
-	q.mu.Lock()
+	metrics.Lock(&q.mu, q.observer)
 	// Defer the unlock to after the func exits
 	defer q.mu.Unlock()
 
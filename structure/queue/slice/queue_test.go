@@ -0,0 +1,36 @@
+package slice
+
+import (
+	"testing"
+
+	"github.com/noriah/go-code/structure/metrics"
+)
+
+func TestSliceQueueObserver(t *testing.T) {
+	counter := metrics.NewCounter()
+
+	// Past the starting size, so the queue grows while observed
+	q := New()
+	q.SetObserver(counter)
+	for i := 0; i < defaultSliceSize+4; i++ {
+		q.Push(i)
+	}
+	q.Pop()
+	q.Clear()
+	q.Pop()
+
+	snap := counter.Snapshot()
+
+	if snap.Enqueues != defaultSliceSize+4 || snap.Dequeues != 1 || snap.Full != 0 || snap.Empty != 1 {
+		t.Errorf("expected %d/1/0/1 enqueues/dequeues/full/empty, got %d/%d/%d/%d",
+			defaultSliceSize+4, snap.Enqueues, snap.Dequeues, snap.Full, snap.Empty)
+	}
+
+	if snap.MaxDepth != defaultSliceSize+4 {
+		t.Errorf("expected max depth %d, got %d", defaultSliceSize+4, snap.MaxDepth)
+	}
+
+	if snap.LockWaitCount == 0 {
+		t.Error("expected lock waits to be reported")
+	}
+}
@@ -0,0 +1,137 @@
+// Package watermark implements high and low watermarks for queues.
+// A Watermark is a metrics.Observer. Attach it to a queue with SetObserver
+// and it will tell producers when the queue is getting full, well before
+// the queue starts turning values away.
+//
+// The two marks give hysteresis. Once depth reaches the high mark, the
+// watermark is raised and stays raised until depth falls to the low mark.
+// Each crossing fires exactly once, no matter how long the queue sits
+// between the marks.
+package watermark
+
+import (
+	"sync"
+	"time"
+)
+
+// Watermark tracks a queue depth against a high and a low mark
+type Watermark struct {
+	mu     sync.Mutex     // Mutex for safe parallel operations
+	low    int            // Depth at or below which we lower the mark
+	high   int            // Depth at or above which we raise the mark
+	raised bool           // Are we above the high mark
+	onHigh func(size int) // Called when the mark is raised
+	onLow  func(size int) // Called when the mark is lowered
+	highCh chan struct{}  // Signalled when the mark is raised
+	lowCh  chan struct{}  // Signalled when the mark is lowered
+}
+
+// New returns a new Watermark.
+// low must be at least 0 and less than high.
+func New(low, high int) *Watermark {
+	if low < 0 || high <= low {
+		panic("Watermark needs 0 <= low < high")
+	}
+
+	return &Watermark{
+		low:    low,
+		high:   high,
+		highCh: make(chan struct{}, 1),
+		lowCh:  make(chan struct{}, 1),
+	}
+}
+
+// OnHigh sets a function to call when depth reaches the high mark.
+// fn is called on the goroutine that made the queue cross the mark.
+func (w *Watermark) OnHigh(fn func(size int)) {
+	w.mu.Lock()
+	w.onHigh = fn
+	w.mu.Unlock()
+}
+
+// OnLow sets a function to call when depth falls back to the low mark.
+// fn is called on the goroutine that made the queue cross the mark.
+func (w *Watermark) OnLow(fn func(size int)) {
+	w.mu.Lock()
+	w.onLow = fn
+	w.mu.Unlock()
+}
+
+// High returns a channel that receives when depth reaches the high mark.
+// The channel holds one signal. Crossings while a signal is pending are dropped.
+func (w *Watermark) High() <-chan struct{} {
+	return w.highCh
+}
+
+// Low returns a channel that receives when depth falls back to the low mark.
+// The channel holds one signal. Crossings while a signal is pending are dropped.
+func (w *Watermark) Low() <-chan struct{} {
+	return w.lowCh
+}
+
+// IsHigh returns true if the mark is raised
+func (w *Watermark) IsHigh() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.raised
+}
+
+// OnEnqueue raises the mark if size has reached the high mark.
+//
+// A queue Clear is not reported to observers, so a small size while the
+// mark is raised lowers it here too.
+func (w *Watermark) OnEnqueue(size int) {
+	w.update(size)
+}
+
+// OnDequeue lowers the mark if size has fallen to the low mark
+func (w *Watermark) OnDequeue(size int) {
+	w.update(size)
+}
+
+// OnFull does nothing. A full queue has already passed the high mark.
+func (w *Watermark) OnFull() {}
+
+// OnEmpty does nothing. An empty queue has already passed the low mark.
+func (w *Watermark) OnEmpty() {}
+
+// OnLockWait does nothing
+func (w *Watermark) OnLockWait(_ time.Duration) {}
+
+// update moves the mark based on size and fires anything that needs firing
+func (w *Watermark) update(size int) {
+	var fn func(int)
+	var ch chan struct{}
+
+	w.mu.Lock()
+
+	switch {
+	// Crossed up
+	case !w.raised && size >= w.high:
+		w.raised = true
+		fn, ch = w.onHigh, w.highCh
+
+	// Crossed down
+	case w.raised && size <= w.low:
+		w.raised = false
+		fn, ch = w.onLow, w.lowCh
+	}
+
+	w.mu.Unlock()
+
+	// Nothing crossed
+	if ch == nil {
+		return
+	}
+
+	// Signal without blocking. If a signal is already waiting, that will do
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+
+	if fn != nil {
+		fn(size)
+	}
+}
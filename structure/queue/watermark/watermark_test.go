@@ -0,0 +1,88 @@
+package watermark
+
+import (
+	"testing"
+
+	"github.com/noriah/go-code/structure/metrics"
+	"github.com/noriah/go-code/structure/queue/channel"
+	"github.com/noriah/go-code/structure/queue/linked"
+)
+
+func TestWatermarkLinked(t *testing.T) {
+	queue := linked.New(10)
+	mark := New(2, 8)
+	counter := metrics.NewCounter()
+	queue.SetObserver(metrics.Multi(mark, counter))
+
+	var highs, lows []int
+	mark.OnHigh(func(size int) { highs = append(highs, size) })
+	mark.OnLow(func(size int) { lows = append(lows, size) })
+
+	for i := 0; i < 10; i++ {
+		queue.Enqueue(i)
+	}
+
+	if !mark.IsHigh() {
+		t.Error("expected mark to be raised")
+	}
+
+	// Drain down to 3. Still above low, so nothing should fire
+	for i := 0; i < 7; i++ {
+		queue.Dequeue()
+	}
+
+	if len(lows) != 0 {
+		t.Errorf("expected no low crossing yet, got %v", lows)
+	}
+
+	queue.Dequeue()
+
+	if len(highs) != 1 || highs[0] != 8 {
+		t.Errorf("expected one high crossing at %d, got %v", 8, highs)
+	}
+
+	if len(lows) != 1 || lows[0] != 2 {
+		t.Errorf("expected one low crossing at %d, got %v", 2, lows)
+	}
+
+	if snap := counter.Snapshot(); snap.Enqueues != 10 {
+		t.Errorf("expected counter to see %d enqueues, got %d", 10, snap.Enqueues)
+	}
+}
+
+func TestWatermarkChannel(t *testing.T) {
+	queue := channel.New(4)
+	mark := New(0, queue.Capacity()-1)
+	queue.SetObserver(mark)
+
+	queue.Push(1)
+	queue.Push(2)
+
+	select {
+	case <-mark.High():
+		t.Fatal("unexpected high signal")
+	default:
+	}
+
+	queue.Push(3)
+
+	select {
+	case <-mark.High():
+	default:
+		t.Fatal("expected high signal")
+	}
+
+	queue.Pop()
+	queue.Pop()
+	queue.Pop()
+
+	select {
+	case <-mark.Low():
+	default:
+		t.Fatal("expected low signal")
+	}
+
+	if mark.IsHigh() {
+		t.Error("expected mark to be lowered")
+	}
+}
@@ -0,0 +1,273 @@
+package skiplist
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/noriah/go-code/structure/tree"
+)
+
+// cnode is an entry in a Concurrent list. Every pointer in it is read and
+// written atomically.
+type cnode struct {
+	key   interface{}      // Key this node is sorted by. Never changes
+	value unsafe.Pointer   // *interface{} holding the value
+	next  []unsafe.Pointer // *cnode for each level this node is on
+}
+
+// Concurrent implements a Skip List where reads never lock.
+//
+// Writers take a mutex, so only one changes the list at a time. They
+// publish every change with a single atomic store, in an order that keeps
+// the list whole at each step:
+//
+//   - Put fills in the new node's next pointers first, then links it in
+//     from the bottom level up. A reader that sees it on any level will
+//     also find it below.
+//   - Delete unlinks a node from the top level down, and leaves the node's
+//     own next pointers alone. A reader standing on it walks off into the
+//     rest of the list as if it were still there.
+//
+// Reads see each key as it was at some moment during the read, but a Range
+// is not a snapshot. Keys added or removed while it runs may or may not
+// be seen.
+type Concurrent struct {
+	mu      sync.Mutex     // Mutex for writers. Readers never take it
+	head    unsafe.Pointer // *cnode sentinel in front of the list
+	level   int32          // Number of levels in use
+	count   int64          // Total number of nodes minus head
+	compare tree.Compare   // How we order keys
+	rng     *rand.Rand     // Coin for picking node levels. Writers only
+}
+
+// NewConcurrent returns a new Skip List with lock-free reads,
+// ordered by compare
+func NewConcurrent(compare tree.Compare) *Concurrent {
+	if compare == nil {
+		panic("nil compare provided")
+	}
+
+	return &Concurrent{
+		head:    unsafe.Pointer(newHead()),
+		level:   1,
+		compare: compare,
+		rng:     rand.New(rand.NewSource(rand.Int63())),
+	}
+}
+
+// Size returns the number of keys in the list
+func (c *Concurrent) Size() int {
+	return int(atomic.LoadInt64(&c.count))
+}
+
+// IsEmpty checks for list emptiness
+func (c *Concurrent) IsEmpty() bool {
+	return c.Size() == 0
+}
+
+// Clear empties the list
+func (c *Concurrent) Clear() {
+	c.mu.Lock()
+
+	atomic.StorePointer(&c.head, unsafe.Pointer(newHead()))
+	atomic.StoreInt32(&c.level, 1)
+	atomic.StoreInt64(&c.count, 0)
+
+	c.mu.Unlock()
+}
+
+// Put sets the value for key, adding the key if it is new.
+//
+// Time: O(log n) expected
+func (c *Concurrent) Put(key, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var head = c.loadHead()
+	var prev [maxLevel]*cnode
+
+	if n := c.search(head, key, &prev); n != nil {
+		atomic.StorePointer(&n.value, unsafe.Pointer(&value))
+		return
+	}
+
+	var level = randomLevel(c.rng)
+	var current = int(atomic.LoadInt32(&c.level))
+
+	for i := current; i < level; i++ {
+		prev[i] = head
+	}
+
+	var n = &cnode{
+		key:   key,
+		value: unsafe.Pointer(&value),
+		next:  make([]unsafe.Pointer, level),
+	}
+
+	// Nobody can see n yet, so point it at its successors first
+	for i := 0; i < level; i++ {
+		n.next[i] = atomic.LoadPointer(&prev[i].next[i])
+	}
+
+	// Then link it in from the bottom up
+	for i := 0; i < level; i++ {
+		atomic.StorePointer(&prev[i].next[i], unsafe.Pointer(n))
+	}
+
+	if level > current {
+		atomic.StoreInt32(&c.level, int32(level))
+	}
+
+	atomic.AddInt64(&c.count, 1)
+}
+
+// Get returns the value for key.
+// Returns nil and error if the key is not in the list.
+//
+// Time: O(log n) expected
+func (c *Concurrent) Get(key interface{}) (interface{}, error) {
+	if n := c.search(c.loadHead(), key, nil); n != nil {
+		return *(*interface{})(atomic.LoadPointer(&n.value)), nil
+	}
+
+	return nil, errorKeyNotFound
+}
+
+// Has returns true if key is in the list
+func (c *Concurrent) Has(key interface{}) bool {
+	var _, err = c.Get(key)
+
+	return err == nil
+}
+
+// Delete removes key from the list.
+// Returns error if the key is not in the list.
+//
+// Time: O(log n) expected
+func (c *Concurrent) Delete(key interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var head = c.loadHead()
+	var prev [maxLevel]*cnode
+
+	var n = c.search(head, key, &prev)
+	if n == nil {
+		return errorKeyNotFound
+	}
+
+	// Unlink from the top down. n.next stays as it is
+	for i := len(n.next) - 1; i >= 0; i-- {
+		atomic.StorePointer(&prev[i].next[i], atomic.LoadPointer(&n.next[i]))
+	}
+
+	// Drop levels nothing is on any more
+	var level = atomic.LoadInt32(&c.level)
+	for level > 1 && atomic.LoadPointer(&head.next[level-1]) == nil {
+		level--
+	}
+
+	atomic.StoreInt32(&c.level, level)
+	atomic.AddInt64(&c.count, -1)
+
+	return nil
+}
+
+// Min returns the smallest key and its value
+func (c *Concurrent) Min() (key, value interface{}, err error) {
+	var n = next(c.loadHead(), 0)
+	if n == nil {
+		return nil, nil, errorListEmpty
+	}
+
+	return n.key, *(*interface{})(atomic.LoadPointer(&n.value)), nil
+}
+
+// Max returns the biggest key and its value
+//
+// Time: O(log n) expected
+func (c *Concurrent) Max() (key, value interface{}, err error) {
+	var head = c.loadHead()
+	var n = head
+
+	for i := int(atomic.LoadInt32(&c.level)) - 1; i >= 0; i-- {
+		for m := next(n, i); m != nil; m = next(n, i) {
+			n = m
+		}
+	}
+
+	if n == head {
+		return nil, nil, errorListEmpty
+	}
+
+	return n.key, *(*interface{})(atomic.LoadPointer(&n.value)), nil
+}
+
+// Range visits every key in [from, to] in ascending order.
+// Nothing is locked, so fn may call back into the list, writes included.
+//
+// Time: O(log n + m) expected, where m is the number of keys visited
+func (c *Concurrent) Range(from, to interface{}, fn tree.Visit) {
+	for n := c.seek(c.loadHead(), from); n != nil && c.compare(n.key, to) <= 0; n = next(n, 0) {
+		if !fn(n.key, *(*interface{})(atomic.LoadPointer(&n.value))) {
+			return
+		}
+	}
+}
+
+// Helper Methods
+// These methods are used internally.
+
+// newHead returns a sentinel on every level
+func newHead() *cnode {
+	return &cnode{next: make([]unsafe.Pointer, maxLevel)}
+}
+
+// loadHead returns the current sentinel
+func (c *Concurrent) loadHead() *cnode {
+	return (*cnode)(atomic.LoadPointer(&c.head))
+}
+
+// next returns the node after n on level i, or nil
+func next(n *cnode, i int) *cnode {
+	return (*cnode)(atomic.LoadPointer(&n.next[i]))
+}
+
+// seek returns the first node not smaller than key, or nil
+func (c *Concurrent) seek(head *cnode, key interface{}) *cnode {
+	var n, m = head, (*cnode)(nil)
+
+	// m is left on the node that stopped the bottom level. Loading next
+	// again could find a smaller key linked in since
+	for i := int(atomic.LoadInt32(&c.level)) - 1; i >= 0; i-- {
+		for m = next(n, i); m != nil && c.compare(m.key, key) < 0; m = next(n, i) {
+			n = m
+		}
+	}
+
+	return m
+}
+
+// search returns the node holding key, or nil. If prev is given, it is
+// filled with the last node before key on each level in use.
+func (c *Concurrent) search(head *cnode, key interface{}, prev *[maxLevel]*cnode) *cnode {
+	var n, m = head, (*cnode)(nil)
+
+	for i := int(atomic.LoadInt32(&c.level)) - 1; i >= 0; i-- {
+		for m = next(n, i); m != nil && c.compare(m.key, key) < 0; m = next(n, i) {
+			n = m
+		}
+
+		if prev != nil {
+			prev[i] = n
+		}
+	}
+
+	if m != nil && c.compare(m.key, key) == 0 {
+		return m
+	}
+
+	return nil
+}
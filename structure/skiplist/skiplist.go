@@ -0,0 +1,271 @@
+// Package skiplist holds implementation for a Skip List ordered map.
+// A skip list is a sorted linked list with express lanes. Every node is on
+// the bottom level, about a quarter of them are also on the level above,
+// a quarter of those on the next, and so on. A search runs along the top
+// level until it would overshoot, then drops down a level, skipping most
+// of the list on the way.
+//
+// The levels come from a coin toss rather than from rebalancing, so the
+// O(log n) bounds are expected, not worst case. In exchange every change
+// is local to a node's neighbours, which is what lets Concurrent serve
+// reads without taking a lock.
+package skiplist
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+
+	"github.com/noriah/go-code/structure/tree"
+)
+
+// The most levels a node can have. Enough for 4^32 keys
+const maxLevel = 32
+
+// An error to be returned when Min/Max-ing on an empty list
+var errorListEmpty = errors.New("empty list")
+
+// An error to be returned when a key is not in the list
+var errorKeyNotFound = errors.New("key not found")
+
+// node is an entry in the list
+type node struct {
+	key   interface{} // Key this node is sorted by
+	value interface{} // Value held for the key
+	next  []*node     // Next node on each level this node is on
+}
+
+// List implements a Skip List
+type List struct {
+	mu      sync.Mutex   // Mutex for safe parallel operations
+	head    *node        // Sentinel in front of the list, on every level
+	level   int          // Number of levels in use
+	count   int          // Total number of nodes minus head
+	compare tree.Compare // How we order keys
+	rng     *rand.Rand   // Coin for picking node levels
+}
+
+// New returns a new Skip List ordered by compare
+func New(compare tree.Compare) *List {
+	if compare == nil {
+		panic("nil compare provided")
+	}
+
+	return &List{
+		head:    &node{next: make([]*node, maxLevel)},
+		level:   1,
+		compare: compare,
+		rng:     rand.New(rand.NewSource(rand.Int63())),
+	}
+}
+
+// Size returns the number of keys in the list
+func (l *List) Size() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.count
+}
+
+// IsEmpty checks for list emptiness
+func (l *List) IsEmpty() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.count == 0
+}
+
+// Clear empties the list
+func (l *List) Clear() {
+	l.mu.Lock()
+
+	l.head = &node{next: make([]*node, maxLevel)}
+	l.level = 1
+	l.count = 0
+
+	l.mu.Unlock()
+}
+
+// Put sets the value for key, adding the key if it is new.
+//
+// Time: O(log n) expected
+func (l *List) Put(key, value interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var prev [maxLevel]*node
+	if n := l.search(key, &prev); n != nil {
+		n.value = value
+		return
+	}
+
+	var level = randomLevel(l.rng)
+	if level > l.level {
+		for i := l.level; i < level; i++ {
+			prev[i] = l.head
+		}
+
+		l.level = level
+	}
+
+	var n = &node{key: key, value: value, next: make([]*node, level)}
+
+	for i := 0; i < level; i++ {
+		n.next[i] = prev[i].next[i]
+		prev[i].next[i] = n
+	}
+
+	l.count++
+}
+
+// Get returns the value for key.
+// Returns nil and error if the key is not in the list.
+//
+// Time: O(log n) expected
+func (l *List) Get(key interface{}) (interface{}, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if n := l.search(key, nil); n != nil {
+		return n.value, nil
+	}
+
+	return nil, errorKeyNotFound
+}
+
+// Has returns true if key is in the list
+func (l *List) Has(key interface{}) bool {
+	var _, err = l.Get(key)
+
+	return err == nil
+}
+
+// Delete removes key from the list.
+// Returns error if the key is not in the list.
+//
+// Time: O(log n) expected
+func (l *List) Delete(key interface{}) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var prev [maxLevel]*node
+
+	var n = l.search(key, &prev)
+	if n == nil {
+		return errorKeyNotFound
+	}
+
+	for i := range n.next {
+		prev[i].next[i] = n.next[i]
+	}
+
+	// Drop levels nothing is on any more
+	for l.level > 1 && l.head.next[l.level-1] == nil {
+		l.level--
+	}
+
+	l.count--
+
+	return nil
+}
+
+// Min returns the smallest key and its value
+func (l *List) Min() (key, value interface{}, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var n = l.head.next[0]
+	if n == nil {
+		return nil, nil, errorListEmpty
+	}
+
+	return n.key, n.value, nil
+}
+
+// Max returns the biggest key and its value
+//
+// Time: O(log n) expected
+func (l *List) Max() (key, value interface{}, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.count == 0 {
+		return nil, nil, errorListEmpty
+	}
+
+	// Run right as far as we can on each level
+	var n = l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for n.next[i] != nil {
+			n = n.next[i]
+		}
+	}
+
+	return n.key, n.value, nil
+}
+
+// Range visits every key in [from, to] in ascending order.
+// The list is locked for the whole walk. fn must not call back into the list.
+//
+// Time: O(log n + m) expected, where m is the number of keys visited
+func (l *List) Range(from, to interface{}, fn tree.Visit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for n := l.seek(from); n != nil && l.compare(n.key, to) <= 0; n = n.next[0] {
+		if !fn(n.key, n.value) {
+			return
+		}
+	}
+}
+
+// Helper Methods
+// These methods are used internally.
+
+// seek returns the first node not smaller than key, or nil
+func (l *List) seek(key interface{}) *node {
+	var n = l.head
+
+	for i := l.level - 1; i >= 0; i-- {
+		for n.next[i] != nil && l.compare(n.next[i].key, key) < 0 {
+			n = n.next[i]
+		}
+	}
+
+	return n.next[0]
+}
+
+// search returns the node holding key, or nil. If prev is given, it is
+// filled with the last node before key on each level.
+func (l *List) search(key interface{}, prev *[maxLevel]*node) *node {
+	var n = l.head
+
+	for i := l.level - 1; i >= 0; i-- {
+		for n.next[i] != nil && l.compare(n.next[i].key, key) < 0 {
+			n = n.next[i]
+		}
+
+		if prev != nil {
+			prev[i] = n
+		}
+	}
+
+	if n = n.next[0]; n != nil && l.compare(n.key, key) == 0 {
+		return n
+	}
+
+	return nil
+}
+
+// randomLevel picks how many levels a new node is on. Each level past
+// the first has a 1 in 4 chance
+func randomLevel(rng *rand.Rand) int {
+	var level = 1
+
+	// Two bits per toss, both zero is a 1 in 4 chance
+	for bits := rng.Uint64(); level < maxLevel && bits&3 == 0; bits >>= 2 {
+		level++
+	}
+
+	return level
+}
@@ -0,0 +1,267 @@
+package skiplist
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/noriah/go-code/structure/tree"
+	"github.com/noriah/go-code/structure/tree/avl"
+	"github.com/noriah/go-code/structure/tree/llrb"
+)
+
+// orderedMap is what both lists, and the balanced trees, have in common
+type orderedMap interface {
+	Size() int
+	Put(key, value interface{})
+	Get(key interface{}) (interface{}, error)
+	Delete(key interface{}) error
+	Min() (interface{}, interface{}, error)
+	Max() (interface{}, interface{}, error)
+	Range(from, to interface{}, fn tree.Visit)
+}
+
+var implementations = []struct {
+	name string
+	new  func() orderedMap
+}{
+	{"List", func() orderedMap { return New(tree.Ints) }},
+	{"Concurrent", func() orderedMap { return NewConcurrent(tree.Ints) }},
+}
+
+// rangeKeys returns the keys Range visits in [from, to]
+func rangeKeys(m orderedMap, from, to int) []int {
+	var keys []int
+	m.Range(from, to, func(key, value interface{}) bool {
+		keys = append(keys, key.(int))
+		return true
+	})
+	return keys
+}
+
+func TestRandomized(t *testing.T) {
+	const count = 2000
+
+	for _, impl := range implementations {
+		m := impl.new()
+		expect := map[int]int{}
+		rng := rand.New(rand.NewSource(1))
+
+		for i := 0; i < 4*count; i++ {
+			key := rng.Intn(count)
+
+			if rng.Intn(3) == 0 {
+				err := m.Delete(key)
+				if _, ok := expect[key]; ok != (err == nil) {
+					t.Fatalf("%s: Delete(%d): key present %t, got error %v", impl.name, key, ok, err)
+				}
+
+				delete(expect, key)
+			} else {
+				m.Put(key, i)
+				expect[key] = i
+			}
+		}
+
+		if m.Size() != len(expect) {
+			t.Fatalf("%s: expected size %d, got %d", impl.name, len(expect), m.Size())
+		}
+
+		var sorted []int
+		for key, value := range expect {
+			sorted = append(sorted, key)
+
+			if got, err := m.Get(key); err != nil || got != value {
+				t.Errorf("%s: Get(%d): expected %d, got %v (%v)", impl.name, key, value, got, err)
+			}
+		}
+
+		sort.Ints(sorted)
+
+		if got := rangeKeys(m, 0, count); fmt.Sprint(got) != fmt.Sprint(sorted) {
+			t.Fatalf("%s: Range over everything: expected %v, got %v", impl.name, sorted, got)
+		}
+
+		if key, _, _ := m.Min(); key != sorted[0] {
+			t.Errorf("%s: Min: expected %d, got %v", impl.name, sorted[0], key)
+		}
+
+		if key, _, _ := m.Max(); key != sorted[len(sorted)-1] {
+			t.Errorf("%s: Max: expected %d, got %v", impl.name, sorted[len(sorted)-1], key)
+		}
+
+		for _, key := range sorted {
+			m.Delete(key)
+		}
+
+		if _, _, err := m.Min(); err != errorListEmpty {
+			t.Errorf("%s: expected %v, got %v", impl.name, errorListEmpty, err)
+		}
+
+		if _, _, err := m.Max(); err != errorListEmpty {
+			t.Errorf("%s: expected %v, got %v", impl.name, errorListEmpty, err)
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	for _, impl := range implementations {
+		m := impl.new()
+		for i := 0; i < 100; i += 10 {
+			m.Put(i, nil)
+		}
+
+		tests := []struct {
+			from, to int
+			expect   string
+		}{
+			{25, 65, "[30 40 50 60]"},
+			{30, 60, "[30 40 50 60]"},
+			{-5, 5, "[0]"},
+			{95, 200, "[]"},
+			{60, 30, "[]"},
+		}
+
+		for _, test := range tests {
+			if got := fmt.Sprint(rangeKeys(m, test.from, test.to)); got != test.expect {
+				t.Errorf("%s: Range(%d, %d): expected %s, got %s", impl.name, test.from, test.to, test.expect, got)
+			}
+		}
+	}
+}
+
+func TestConcurrentReads(t *testing.T) {
+	const count = 1000
+
+	c := NewConcurrent(tree.Ints)
+
+	// Even keys stay put the whole time. Odd keys come and go
+	for i := 0; i < count; i += 2 {
+		c.Put(i, i)
+	}
+
+	var wg sync.WaitGroup
+	var done = make(chan struct{})
+
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				for i := 0; i < count; i += 2 {
+					if value, err := c.Get(i); err != nil || value != i {
+						t.Errorf("Get(%d): expected %d, got %v (%v)", i, i, value, err)
+						return
+					}
+				}
+
+				// Every even key in [from, to] is there the whole time,
+				// so Range must see each one, and nothing outside
+				const from, to = count / 4, 3 * count / 4
+
+				var last, even = -1, 0
+				c.Range(from, to, func(key, value interface{}) bool {
+					if k := key.(int); k <= last || k < from || k > to {
+						t.Errorf("Range(%d, %d): key %d after %d", from, to, k, last)
+					}
+
+					if last = key.(int); last%2 == 0 {
+						even++
+					}
+					return true
+				})
+
+				if even != (to-from)/2+1 {
+					t.Errorf("Range(%d, %d): expected %d even keys, got %d", from, to, (to-from)/2+1, even)
+					return
+				}
+			}
+		}()
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 20000; i++ {
+		key := rng.Intn(count/2)*2 + 1
+
+		if rng.Intn(2) == 0 {
+			c.Put(key, key)
+		} else {
+			c.Delete(key)
+		}
+	}
+
+	close(done)
+	wg.Wait()
+}
+
+// benchMaps is the skip lists against the balanced trees
+var benchMaps = []struct {
+	name string
+	new  func() orderedMap
+}{
+	{"skiplist.List", func() orderedMap { return New(tree.Ints) }},
+	{"skiplist.Concurrent", func() orderedMap { return NewConcurrent(tree.Ints) }},
+	{"avl.Tree", func() orderedMap { return avl.New(tree.Ints) }},
+	{"llrb.Tree", func() orderedMap { return llrb.New(tree.Ints) }},
+}
+
+func BenchmarkPut(b *testing.B) {
+	for _, bm := range benchMaps {
+		b.Run(bm.name, func(b *testing.B) {
+			keys := rand.New(rand.NewSource(1)).Perm(b.N)
+			m := bm.new()
+
+			b.ResetTimer()
+			for _, key := range keys {
+				m.Put(key, nil)
+			}
+		})
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	const count = 1 << 16
+
+	for _, bm := range benchMaps {
+		b.Run(bm.name, func(b *testing.B) {
+			m := bm.new()
+			for _, key := range rand.Perm(count) {
+				m.Put(key, nil)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				m.Get(i % count)
+			}
+		})
+	}
+}
+
+func BenchmarkParallelGet(b *testing.B) {
+	const count = 1 << 16
+
+	for _, bm := range benchMaps {
+		b.Run(bm.name, func(b *testing.B) {
+			m := bm.new()
+			for _, key := range rand.Perm(count) {
+				m.Put(key, nil)
+			}
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 0; pb.Next(); i++ {
+					m.Get(i % count)
+				}
+			})
+		})
+	}
+}
@@ -5,6 +5,8 @@ package linked
 import (
 	"errors"
 	"sync"
+
+	"github.com/noriah/go-code/structure/metrics"
 )
 
 // An error to be returned when Pop/Peek-ing on an empty stack
@@ -31,6 +33,9 @@ type Stack struct {
 
 	// our number of items in the stack
 	count int
+
+	// optional observer. nil means no instrumentation
+	observer metrics.Observer
 }
 
 // New returns a new Linked Stack
@@ -46,7 +51,7 @@ func New(values ...interface{}) *Stack {
 	var newStack = &Stack{}
 
 	// Add any values we may have been passed to the stack
-	newStack.Append(values)
+	newStack.Append(values...)
 
 	// Return the new stack
 	return newStack
@@ -60,7 +65,7 @@ func (s *Stack) Push(value interface{}) {
 
 	// Lock the mutex while we are modifying the stack. Prevents someone
 	// Adding a node before we do, and having a messed up stack
-	s.mu.Lock()
+	metrics.Lock(&s.mu, s.observer)
 
 	// Make a new node to be added to the stack
 	s.head = &node{
@@ -75,8 +80,15 @@ func (s *Stack) Push(value interface{}) {
 	// Increment the total items in stack
 	s.count++
 
+	// Hold on to the count so we can report it outside the lock
+	var size = s.count
+
 	// Unlock the mutex
 	s.mu.Unlock()
+
+	if s.observer != nil {
+		s.observer.OnEnqueue(size)
+	}
 }
 
 // Append adds values to the top of the stack by building a mini-stack and
@@ -140,7 +152,7 @@ func (s *Stack) Append(values ...interface{}) {
 	// Lock the mutex so we can be sure to add our new mini-stack to the
 	// end of the actual stack. Without this, we could be in a race condition
 	// where we took long enough to build the mini-stack that another
-	s.mu.Lock()
+	metrics.Lock(&s.mu, s.observer)
 
 	// Set next on the last mini-stack item to point to our real stack top
 	last.next = s.head
@@ -151,8 +163,18 @@ func (s *Stack) Append(values ...interface{}) {
 	// increase our count by number of values
 	s.count += vLen
 
+	// Hold on to the count so we can report it outside the lock
+	var size = s.count
+
 	// Unlock the mutex
 	s.mu.Unlock()
+
+	// Report each value we added, in order
+	if s.observer != nil {
+		for idx = size - vLen + 1; idx <= size; idx++ {
+			s.observer.OnEnqueue(idx)
+		}
+	}
 }
 
 // Pop returns the value on the top of the stack, removing it from the stack
@@ -163,6 +185,11 @@ func (s *Stack) Pop() (interface{}, error) {
 	// If our tail node is the same our our head node, then we have an empty stack
 	if s.head == nil {
 
+		// Let the observer know there was nothing to hand out
+		if s.observer != nil {
+			s.observer.OnEmpty()
+		}
+
 		// Return a nil value and our error
 		return nil, errorStackEmpty
 	}
@@ -172,7 +199,7 @@ func (s *Stack) Pop() (interface{}, error) {
 
 	// Lock the mutex so nobody can modify the stack while we are removing
 	// the head of the stack
-	s.mu.Lock()
+	metrics.Lock(&s.mu, s.observer)
 
 	// assign the current head node to our variable so we don't lose it
 	temp = s.head
@@ -183,9 +210,16 @@ func (s *Stack) Pop() (interface{}, error) {
 	// decrement our count of items in stack
 	s.count--
 
+	// Hold on to the count so we can report it outside the lock
+	var size = s.count
+
 	// Unlock the mutex
 	s.mu.Unlock()
 
+	if s.observer != nil {
+		s.observer.OnDequeue(size)
+	}
+
 	// return the value in our temp node
 	return temp.value, nil
 }
@@ -205,7 +239,7 @@ func (s *Stack) Peek() (interface{}, error) {
 	var temp *node
 
 	// Lock the internal mutex to prevent someone pop-ing while we are peek-ing
-	s.mu.Lock()
+	metrics.Lock(&s.mu, s.observer)
 
 	// Set our temp pointer to be the head item in our stack
 	temp = s.head
@@ -217,6 +251,14 @@ func (s *Stack) Peek() (interface{}, error) {
 	return temp.value, nil
 }
 
+// SetObserver attaches an observer to the stack. Passing nil detaches it.
+// The observer should be set before the stack is shared between goroutines.
+func (s *Stack) SetObserver(o metrics.Observer) {
+	s.mu.Lock()
+	s.observer = o
+	s.mu.Unlock()
+}
+
 // Clear empties the stack.
 // Since the garbage collector cleans up all pointer values once they are no
 // longer referenced, we just need to set our tail pointer to our head node,
@@ -225,7 +267,7 @@ func (s *Stack) Clear() {
 
 	// Lock our mutex so we can be sure to clear the stack before any other
 	// operations happen on it
-	s.mu.Lock()
+	metrics.Lock(&s.mu, s.observer)
 
 	// Update our head node to point nil
 	s.head = nil
@@ -241,7 +283,7 @@ func (s *Stack) Clear() {
 func (s *Stack) Size() int {
 
 	// Lock the mutex so we don't check in the middle of an operation
-	s.mu.Lock()
+	metrics.Lock(&s.mu, s.observer)
 
 	// To avoid assigning a temp variable just for the count, we can defer
 	// the mutex unlock to after we have returned
@@ -1,6 +1,10 @@
 package linked
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/noriah/go-code/structure/metrics"
+)
 
 func TestLinkedStack(t *testing.T) {
 	stack := &Stack{}
@@ -36,3 +40,39 @@ func stackPopHelper(t *testing.T, stack *Stack, expect int) {
 		t.Errorf("expected: %d, got %d", expect, value)
 	}
 }
+
+func TestLinkedStackObserver(t *testing.T) {
+	counter := metrics.NewCounter()
+
+	stack := &Stack{}
+	stack.SetObserver(counter)
+	stack.Append(1, 2, 3)
+	stack.Pop()
+	stack.Clear()
+	stack.Pop()
+
+	snap := counter.Snapshot()
+
+	if snap.Enqueues != 3 || snap.Dequeues != 1 || snap.Empty != 1 {
+		t.Errorf("expected 3/1/1 enqueues/dequeues/empty, got %d/%d/%d",
+			snap.Enqueues, snap.Dequeues, snap.Empty)
+	}
+
+	if snap.MaxDepth != 3 {
+		t.Errorf("expected max depth %d, got %d", 3, snap.MaxDepth)
+	}
+}
+
+func TestLinkedStackNew(t *testing.T) {
+	if size := New().Size(); size != 0 {
+		t.Errorf("expected size %d, got %d", 0, size)
+	}
+
+	stack := New(1, 2, 3)
+
+	if size := stack.Size(); size != 3 {
+		t.Errorf("expected size %d, got %d", 3, size)
+	}
+
+	stackPopHelper(t, stack, 3)
+}
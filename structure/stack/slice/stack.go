@@ -4,8 +4,9 @@ package slice
 
 import (
 	"errors"
-	"fmt"
 	"sync"
+
+	"github.com/noriah/go-code/structure/metrics"
 )
 
 const defaultSliceSize = 16
@@ -24,6 +25,9 @@ type Stack struct {
 
 	// our number of items in the stack. updated on every push and pop
 	count int
+
+	// optional observer. nil means no instrumentation
+	observer metrics.Observer
 }
 
 // New returns a new slice Stack
@@ -37,8 +41,6 @@ func New(values ...interface{}) *Stack {
 	// Add any values we may have been passed to the stack
 	newStack.Append(values...)
 
-	fmt.Println(newStack.array)
-
 	// Return the new stack
 	return newStack
 }
@@ -59,7 +61,7 @@ func (s *Stack) Push(value interface{}) {
 
 	// Lock the mutex while we are modifying the stack. Prevents someone
 	// Adding an item before we do, and having a messed up stack counter
-	s.mu.Lock()
+	metrics.Lock(&s.mu, s.observer)
 
 	if s.count >= cap(s.array) {
 		s.expand()
@@ -70,8 +72,15 @@ func (s *Stack) Push(value interface{}) {
 	// Increment the total items in stack
 	s.count++
 
+	// Hold on to the count so we can report it outside the lock
+	var size = s.count
+
 	// Unlock the mutex
 	s.mu.Unlock()
+
+	if s.observer != nil {
+		s.observer.OnEnqueue(size)
+	}
 }
 
 // Append adds a list of items to the stack
@@ -98,9 +107,11 @@ func (s *Stack) Append(values ...interface{}) {
 		return
 	}
 
-	s.mu.Lock()
+	metrics.Lock(&s.mu, s.observer)
 
-	if s.count+vLen >= cap(s.array) {
+	// Grow until the whole batch fits. One doubling is not enough for a
+	// batch bigger than the stack
+	for s.count+vLen > cap(s.array) {
 		s.expand()
 	}
 
@@ -108,8 +119,18 @@ func (s *Stack) Append(values ...interface{}) {
 
 	s.count += vLen
 
+	// Hold on to the count so we can report it outside the lock
+	var size = s.count
+
 	// Unlock the mutex
 	s.mu.Unlock()
+
+	// Report each value we added, in order
+	if s.observer != nil {
+		for idx := size - vLen + 1; idx <= size; idx++ {
+			s.observer.OnEnqueue(idx)
+		}
+	}
 }
 
 // Pop returns the value on the top of the stack, removing it from the stack
@@ -118,18 +139,28 @@ func (s *Stack) Append(values ...interface{}) {
 func (s *Stack) Pop() (interface{}, error) {
 
 	if s.count <= 0 {
+		if s.observer != nil {
+			s.observer.OnEmpty()
+		}
+
 		return nil, errorStackEmpty
 	}
 
-	s.mu.Lock()
-
-	defer s.mu.Unlock()
+	metrics.Lock(&s.mu, s.observer)
 
 	// decrement our count of items in stack
 	s.count--
 
-	// return the value in our temp node
-	return s.array[s.count], nil
+	// Grab the value and count before we let go of the lock
+	var value, size = s.array[s.count], s.count
+
+	s.mu.Unlock()
+
+	if s.observer != nil {
+		s.observer.OnDequeue(size)
+	}
+
+	return value, nil
 }
 
 // Peek returns the value at the front of the stack.
@@ -143,19 +174,27 @@ func (s *Stack) Peek() (interface{}, error) {
 	}
 
 	// Lock the internal mutex to prevent someone pop-ing while we are peek-ing
-	s.mu.Lock()
+	metrics.Lock(&s.mu, s.observer)
 
 	defer s.mu.Unlock()
 
 	return s.array[s.count-1], nil
 }
 
+// SetObserver attaches an observer to the stack. Passing nil detaches it.
+// The observer should be set before the stack is shared between goroutines.
+func (s *Stack) SetObserver(o metrics.Observer) {
+	s.mu.Lock()
+	s.observer = o
+	s.mu.Unlock()
+}
+
 // Clear empties the stack.
 func (s *Stack) Clear() {
 
 	// Lock our mutex so we can be sure to clear the stack before any other
 	// operations happen on it
-	s.mu.Lock()
+	metrics.Lock(&s.mu, s.observer)
 
 	// Update count to be 0
 	s.count = 0
@@ -168,7 +207,7 @@ func (s *Stack) Clear() {
 func (s *Stack) Size() int {
 
 	// Lock the mutex so we don't check in the middle of an operation
-	s.mu.Lock()
+	metrics.Lock(&s.mu, s.observer)
 
 	// To avoid assigning a temp variable just for the count, we can defer
 	// the mutex unlock to after we have returned
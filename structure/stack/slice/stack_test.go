@@ -1,6 +1,10 @@
 package slice
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/noriah/go-code/structure/metrics"
+)
 
 func TestSliceStack(t *testing.T) {
 	stack := &Stack{}
@@ -36,3 +40,43 @@ func stackPopHelper(t *testing.T, stack *Stack, expect int) {
 		t.Errorf("expected: %d, got %d", expect, value)
 	}
 }
+
+func TestSliceStackAppend(t *testing.T) {
+	stack := &Stack{}
+	stack.Append(1, 2, 3)
+	stack.Append(4, 5, 6, 7, 8, 9, 10)
+
+	if size := stack.Size(); size != 10 {
+		t.Errorf("expected size %d, got %d", 10, size)
+	}
+
+	for i := 10; i > 0; i-- {
+		stackPopHelper(t, stack, i)
+	}
+}
+
+func TestSliceStackObserver(t *testing.T) {
+	counter := metrics.NewCounter()
+
+	stack := &Stack{}
+	stack.SetObserver(counter)
+	stack.Append(1, 2, 3)
+	stack.Pop()
+	stack.Clear()
+	stack.Pop()
+
+	snap := counter.Snapshot()
+
+	if snap.Enqueues != 3 || snap.Dequeues != 1 || snap.Empty != 1 {
+		t.Errorf("expected 3/1/1 enqueues/dequeues/empty, got %d/%d/%d",
+			snap.Enqueues, snap.Dequeues, snap.Empty)
+	}
+
+	if snap.MaxDepth != 3 {
+		t.Errorf("expected max depth %d, got %d", 3, snap.MaxDepth)
+	}
+
+	if snap.LockWaitCount == 0 {
+		t.Error("expected lock waits to be reported")
+	}
+}
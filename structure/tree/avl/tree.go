@@ -0,0 +1,447 @@
+// Package avl holds implementation for an AVL Tree.
+// An AVL tree is a binary search tree that keeps itself balanced. At every
+// node, the heights of the two subtrees differ by at most one. When a Put
+// or Delete breaks that, one or two rotations on the way back up fix it.
+//
+// Each node also keeps the size of its subtree, which gives Rank and
+// Select in O(log n).
+package avl
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/noriah/go-code/structure/tree"
+)
+
+// An error to be returned when Min/Max-ing on an empty tree
+var errorTreeEmpty = errors.New("empty tree")
+
+// An error to be returned when a key is not in the tree
+var errorKeyNotFound = errors.New("key not found")
+
+// An error to be returned when Select-ing past the end of the tree
+var errorOutOfRange = errors.New("index out of range")
+
+// node is an entry in the tree
+type node struct {
+	left   *node       // Subtree of smaller keys
+	right  *node       // Subtree of bigger keys
+	key    interface{} // Key this node is sorted by
+	value  interface{} // Value held for the key
+	height int         // Height of the subtree rooted here. Leaves are 1
+	size   int         // Number of nodes in the subtree rooted here
+}
+
+// Tree implements an AVL Tree
+type Tree struct {
+	mu      sync.Mutex   // Mutex for safe parallel operations
+	root    *node        // Root node of our tree. nil when empty
+	compare tree.Compare // How we order keys
+}
+
+// New returns a new AVL Tree ordered by compare
+func New(compare tree.Compare) *Tree {
+	if compare == nil {
+		panic("nil compare provided")
+	}
+
+	return &Tree{compare: compare}
+}
+
+// Size returns the number of keys in the tree
+func (t *Tree) Size() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return size(t.root)
+}
+
+// IsEmpty checks for tree emptiness
+func (t *Tree) IsEmpty() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.root == nil
+}
+
+// Clear empties the tree
+func (t *Tree) Clear() {
+	t.mu.Lock()
+
+	t.root = nil
+
+	t.mu.Unlock()
+}
+
+// Put sets the value for key, adding the key if it is new.
+//
+// Time: O(log n)
+func (t *Tree) Put(key, value interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.root = t.put(t.root, key, value)
+}
+
+// Get returns the value for key.
+// Returns nil and error if the key is not in the tree.
+//
+// Time: O(log n)
+func (t *Tree) Get(key interface{}) (interface{}, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for n := t.root; n != nil; {
+		var cmp = t.compare(key, n.key)
+
+		switch {
+		case cmp < 0:
+			n = n.left
+		case cmp > 0:
+			n = n.right
+		default:
+			return n.value, nil
+		}
+	}
+
+	return nil, errorKeyNotFound
+}
+
+// Has returns true if key is in the tree
+func (t *Tree) Has(key interface{}) bool {
+	var _, err = t.Get(key)
+
+	return err == nil
+}
+
+// Delete removes key from the tree.
+// Returns error if the key is not in the tree.
+//
+// Time: O(log n)
+func (t *Tree) Delete(key interface{}) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var root, found = t.delete(t.root, key)
+	if !found {
+		return errorKeyNotFound
+	}
+
+	t.root = root
+
+	return nil
+}
+
+// Min returns the smallest key and its value
+func (t *Tree) Min() (key, value interface{}, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.root == nil {
+		return nil, nil, errorTreeEmpty
+	}
+
+	var n = min(t.root)
+
+	return n.key, n.value, nil
+}
+
+// Max returns the biggest key and its value
+func (t *Tree) Max() (key, value interface{}, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.root == nil {
+		return nil, nil, errorTreeEmpty
+	}
+
+	var n = t.root
+	for n.right != nil {
+		n = n.right
+	}
+
+	return n.key, n.value, nil
+}
+
+// Range visits every key in [from, to] in ascending order.
+// The tree is locked for the whole walk. fn must not call back into the tree.
+//
+// Time: O(log n + m) where m is the number of keys visited
+func (t *Tree) Range(from, to interface{}, fn tree.Visit) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.walk(t.root, from, to, fn)
+}
+
+// Rank returns the number of keys smaller than key.
+// key does not have to be in the tree.
+//
+// Time: O(log n)
+func (t *Tree) Rank(key interface{}) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var rank int
+
+	for n := t.root; n != nil; {
+		var cmp = t.compare(key, n.key)
+
+		switch {
+		case cmp < 0:
+			n = n.left
+		case cmp > 0:
+			// Everything on the left, and n itself, is smaller
+			rank += size(n.left) + 1
+			n = n.right
+		default:
+			return rank + size(n.left)
+		}
+	}
+
+	return rank
+}
+
+// Select returns the key with rank i, the i-th smallest counting from 0,
+// and its value.
+//
+// Time: O(log n)
+func (t *Tree) Select(i int) (key, value interface{}, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if i < 0 || i >= size(t.root) {
+		return nil, nil, errorOutOfRange
+	}
+
+	var n = t.root
+
+	for {
+		var left = size(n.left)
+
+		switch {
+		case i < left:
+			n = n.left
+		case i > left:
+			i -= left + 1
+			n = n.right
+		default:
+			return n.key, n.value, nil
+		}
+	}
+}
+
+// Height returns the height of the tree. An empty tree has height 0.
+func (t *Tree) Height() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return height(t.root)
+}
+
+// Helper Methods
+// These methods are used internally.
+
+// put adds key under n and returns the new, balanced, subtree root
+func (t *Tree) put(n *node, key, value interface{}) *node {
+	if n == nil {
+		return &node{key: key, value: value, height: 1, size: 1}
+	}
+
+	var cmp = t.compare(key, n.key)
+
+	switch {
+	case cmp < 0:
+		n.left = t.put(n.left, key, value)
+	case cmp > 0:
+		n.right = t.put(n.right, key, value)
+	default:
+		n.value = value
+		return n
+	}
+
+	return balance(n)
+}
+
+// delete removes key under n and returns the new, balanced, subtree root.
+// found is false if key was not there, in which case nothing changed.
+func (t *Tree) delete(n *node, key interface{}) (*node, bool) {
+	if n == nil {
+		return nil, false
+	}
+
+	var found bool
+	var cmp = t.compare(key, n.key)
+
+	switch {
+	case cmp < 0:
+		n.left, found = t.delete(n.left, key)
+	case cmp > 0:
+		n.right, found = t.delete(n.right, key)
+	default:
+		// Zero or one child. Lift the child into our place
+		if n.left == nil {
+			return n.right, true
+		}
+
+		if n.right == nil {
+			return n.left, true
+		}
+
+		// Two children. Swap in the successor
+		var succ = min(n.right)
+		succ.right = deleteMin(n.right)
+		succ.left = n.left
+
+		return balance(succ), true
+	}
+
+	if !found {
+		return n, false
+	}
+
+	return balance(n), true
+}
+
+// walk visits keys in [from, to] under n. Returns false to stop
+func (t *Tree) walk(n *node, from, to interface{}, fn tree.Visit) bool {
+	if n == nil {
+		return true
+	}
+
+	var cmpFrom, cmpTo = t.compare(from, n.key), t.compare(to, n.key)
+
+	// Smaller keys might be in range
+	if cmpFrom < 0 && !t.walk(n.left, from, to, fn) {
+		return false
+	}
+
+	if cmpFrom <= 0 && cmpTo >= 0 && !fn(n.key, n.value) {
+		return false
+	}
+
+	// Bigger keys might be in range
+	if cmpTo > 0 {
+		return t.walk(n.right, from, to, fn)
+	}
+
+	return true
+}
+
+// deleteMin removes the smallest node under n and returns the new root
+func deleteMin(n *node) *node {
+	if n.left == nil {
+		return n.right
+	}
+
+	n.left = deleteMin(n.left)
+
+	return balance(n)
+}
+
+// min returns the smallest node under n
+func min(n *node) *node {
+	for n.left != nil {
+		n = n.left
+	}
+
+	return n
+}
+
+// balance fixes n's height and size, and rotates if it is out of balance.
+// Returns the new subtree root.
+func balance(n *node) *node {
+	update(n)
+
+	switch bf := height(n.left) - height(n.right); {
+	// Left heavy
+	case bf > 1:
+		// Left-right case. Turn it into left-left first
+		if height(n.left.left) < height(n.left.right) {
+			n.left = rotateLeft(n.left)
+		}
+
+		return rotateRight(n)
+
+	// Right heavy
+	case bf < -1:
+		// Right-left case. Turn it into right-right first
+		if height(n.right.right) < height(n.right.left) {
+			n.right = rotateRight(n.right)
+		}
+
+		return rotateLeft(n)
+	}
+
+	return n
+}
+
+// rotateLeft lifts n's right child above it
+//
+//	  n             r
+//	 / \           / \
+//	a   r   ->    n   c
+//	   / \       / \
+//	  b   c     a   b
+func rotateLeft(n *node) *node {
+	var r = n.right
+
+	n.right = r.left
+	r.left = n
+
+	update(n)
+	update(r)
+
+	return r
+}
+
+// rotateRight lifts n's left child above it
+//
+//	    n         l
+//	   / \       / \
+//	  l   c ->  a   n
+//	 / \           / \
+//	a   b         b   c
+func rotateRight(n *node) *node {
+	var l = n.left
+
+	n.left = l.right
+	l.right = n
+
+	update(n)
+	update(l)
+
+	return l
+}
+
+// update recomputes n's height and size from its children
+func update(n *node) {
+	var l, r = height(n.left), height(n.right)
+
+	if l > r {
+		n.height = l + 1
+	} else {
+		n.height = r + 1
+	}
+
+	n.size = size(n.left) + size(n.right) + 1
+}
+
+// height returns the height of n, or 0 for nil
+func height(n *node) int {
+	if n == nil {
+		return 0
+	}
+
+	return n.height
+}
+
+// size returns the size of n, or 0 for nil
+func size(n *node) int {
+	if n == nil {
+		return 0
+	}
+
+	return n.size
+}
@@ -0,0 +1,85 @@
+package avl
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/noriah/go-code/structure/tree"
+)
+
+// checkInvariants fails the test if the tree is out of order, out of
+// balance, or holds a stale height or size anywhere
+func checkInvariants(t *testing.T, avl *Tree) {
+	t.Helper()
+
+	var check func(n *node, lo, hi interface{}) (int, int)
+	check = func(n *node, lo, hi interface{}) (int, int) {
+		if n == nil {
+			return 0, 0
+		}
+
+		if lo != nil && avl.compare(n.key, lo) <= 0 || hi != nil && avl.compare(n.key, hi) >= 0 {
+			t.Fatalf("key %v out of order, expected between %v and %v", n.key, lo, hi)
+		}
+
+		lh, ls := check(n.left, lo, n.key)
+		rh, rs := check(n.right, n.key, hi)
+
+		if bf := lh - rh; bf < -1 || bf > 1 {
+			t.Fatalf("key %v out of balance, balance factor %d", n.key, bf)
+		}
+
+		h := lh + 1
+		if rh >= lh {
+			h = rh + 1
+		}
+
+		if n.height != h {
+			t.Fatalf("key %v: expected height %d, got %d", n.key, h, n.height)
+		}
+
+		if n.size != ls+rs+1 {
+			t.Fatalf("key %v: expected size %d, got %d", n.key, ls+rs+1, n.size)
+		}
+
+		return h, n.size
+	}
+
+	check(avl.root, nil, nil)
+}
+
+// TestInvariants churns the tree and checks its shape as it goes. The
+// shared tests in structure/tree cover what it holds
+func TestInvariants(t *testing.T) {
+	const count = 2000
+
+	avl := New(tree.Ints)
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 4*count; i++ {
+		if key := rng.Intn(count); rng.Intn(3) == 0 {
+			avl.Delete(key)
+		} else {
+			avl.Put(key, i)
+		}
+
+		if i%50 == 0 {
+			checkInvariants(t, avl)
+		}
+	}
+
+	checkInvariants(t, avl)
+
+	// Ordered input is the worst case for a plain BST
+	avl.Clear()
+	for i := 0; i < count; i++ {
+		avl.Put(i, nil)
+	}
+
+	checkInvariants(t, avl)
+
+	// An AVL tree is never taller than about 1.44 log2(n)
+	if h := avl.Height(); h > 16 {
+		t.Errorf("expected height at most %d for %d ordered keys, got %d", 16, count, h)
+	}
+}
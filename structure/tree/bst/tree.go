@@ -0,0 +1,459 @@
+// Package bst holds implementation for an unbalanced Binary Search Tree.
+// Each node holds a key and a value. Every key in a node's left subtree
+// is smaller than its key, and every key in its right subtree is bigger.
+//
+// Nothing is done to keep the tree balanced. Keys inserted in order give
+// a tree that is really a linked list, and every operation becomes O(n).
+package bst
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/noriah/go-code/structure/queue/linked"
+	linkedstack "github.com/noriah/go-code/structure/stack/linked"
+	"github.com/noriah/go-code/structure/tree"
+)
+
+// An error to be returned when Min/Max-ing on an empty tree
+var errorTreeEmpty = errors.New("empty tree")
+
+// An error to be returned when a key is not in the tree
+var errorKeyNotFound = errors.New("key not found")
+
+// node is an entry in the tree
+type node struct {
+	left  *node       // Subtree of smaller keys
+	right *node       // Subtree of bigger keys
+	key   interface{} // Key this node is sorted by
+	value interface{} // Value held for the key
+}
+
+// Tree implements an unbalanced Binary Search Tree
+type Tree struct {
+	mu      sync.Mutex   // Mutex for safe parallel operations
+	root    *node        // Root node of our tree. nil when empty
+	count   int          // Total number of nodes
+	compare tree.Compare // How we order keys
+}
+
+// New returns a new Binary Search Tree ordered by compare
+func New(compare tree.Compare) *Tree {
+	if compare == nil {
+		panic("nil compare provided")
+	}
+
+	return &Tree{compare: compare}
+}
+
+// Size returns the number of keys in the tree
+func (t *Tree) Size() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.count
+}
+
+// IsEmpty checks for tree emptiness
+func (t *Tree) IsEmpty() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.root == nil
+}
+
+// Clear empties the tree.
+// Dropping the root is enough. The garbage collector takes care of the rest.
+func (t *Tree) Clear() {
+	t.mu.Lock()
+
+	t.root = nil
+	t.count = 0
+
+	t.mu.Unlock()
+}
+
+// Put sets the value for key, adding the key if it is new.
+//
+// Time: O(h) where h is the height of the tree
+func (t *Tree) Put(key, value interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Walk down holding the link we may need to fill in
+	var link = &t.root
+
+	for *link != nil {
+		var cmp = t.compare(key, (*link).key)
+
+		switch {
+		case cmp < 0:
+			link = &(*link).left
+		case cmp > 0:
+			link = &(*link).right
+		default:
+			// Already here. Just update the value
+			(*link).value = value
+			return
+		}
+	}
+
+	*link = &node{key: key, value: value}
+	t.count++
+}
+
+// Get returns the value for key.
+// Returns nil and error if the key is not in the tree.
+//
+// Time: O(h)
+func (t *Tree) Get(key interface{}) (interface{}, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if n := t.find(key); n != nil {
+		return n.value, nil
+	}
+
+	return nil, errorKeyNotFound
+}
+
+// Has returns true if key is in the tree
+func (t *Tree) Has(key interface{}) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.find(key) != nil
+}
+
+// Delete removes key from the tree.
+// Returns error if the key is not in the tree.
+//
+// A node with two children is replaced by its successor, the smallest key
+// in its right subtree (Hibbard deletion).
+//
+// Time: O(h)
+func (t *Tree) Delete(key interface{}) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Find the link pointing at our node
+	var link = &t.root
+
+	for *link != nil {
+		var cmp = t.compare(key, (*link).key)
+
+		if cmp == 0 {
+			break
+		}
+
+		if cmp < 0 {
+			link = &(*link).left
+		} else {
+			link = &(*link).right
+		}
+	}
+
+	var n = *link
+	if n == nil {
+		return errorKeyNotFound
+	}
+
+	switch {
+	// Zero or one child. Lift the child into our place
+	case n.left == nil:
+		*link = n.right
+	case n.right == nil:
+		*link = n.left
+
+	// Two children. Unhook the successor and put it in our place
+	default:
+		var succLink = &n.right
+		for (*succLink).left != nil {
+			succLink = &(*succLink).left
+		}
+
+		var succ = *succLink
+		*succLink = succ.right
+
+		succ.left, succ.right = n.left, n.right
+		*link = succ
+	}
+
+	t.count--
+
+	return nil
+}
+
+// Min returns the smallest key and its value
+//
+// Time: O(h)
+func (t *Tree) Min() (key, value interface{}, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.root == nil {
+		return nil, nil, errorTreeEmpty
+	}
+
+	var n = t.root
+	for n.left != nil {
+		n = n.left
+	}
+
+	return n.key, n.value, nil
+}
+
+// Max returns the biggest key and its value
+//
+// Time: O(h)
+func (t *Tree) Max() (key, value interface{}, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.root == nil {
+		return nil, nil, errorTreeEmpty
+	}
+
+	var n = t.root
+	for n.right != nil {
+		n = n.right
+	}
+
+	return n.key, n.value, nil
+}
+
+// Floor returns the biggest key <= key, and its value.
+// Returns error if every key is bigger.
+//
+// Time: O(h)
+func (t *Tree) Floor(key interface{}) (interface{}, interface{}, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var best *node
+
+	for n := t.root; n != nil; {
+		var cmp = t.compare(key, n.key)
+
+		switch {
+		case cmp == 0:
+			return n.key, n.value, nil
+		case cmp < 0:
+			n = n.left
+		default:
+			// n fits, but there may be a closer one on the right
+			best = n
+			n = n.right
+		}
+	}
+
+	if best == nil {
+		return nil, nil, errorKeyNotFound
+	}
+
+	return best.key, best.value, nil
+}
+
+// Ceiling returns the smallest key >= key, and its value.
+// Returns error if every key is smaller.
+//
+// Time: O(h)
+func (t *Tree) Ceiling(key interface{}) (interface{}, interface{}, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var best *node
+
+	for n := t.root; n != nil; {
+		var cmp = t.compare(key, n.key)
+
+		switch {
+		case cmp == 0:
+			return n.key, n.value, nil
+		case cmp > 0:
+			n = n.right
+		default:
+			// n fits, but there may be a closer one on the left
+			best = n
+			n = n.left
+		}
+	}
+
+	if best == nil {
+		return nil, nil, errorKeyNotFound
+	}
+
+	return best.key, best.value, nil
+}
+
+// Height returns the number of nodes on the longest path from the root.
+// An empty tree has height 0.
+func (t *Tree) Height() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return height(t.root)
+}
+
+// Traversals
+// The tree is locked for the whole walk. fn must not call back into the tree.
+
+// InOrder visits every key in ascending order.
+// It walks iteratively, using a linked stack to remember the way back up.
+func (t *Tree) InOrder(fn tree.Visit) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var stack = linkedstack.New()
+	var n = t.root
+
+	for n != nil || !stack.IsEmpty() {
+		// Go as far left as we can, remembering each node on the way
+		for ; n != nil; n = n.left {
+			stack.Push(n)
+		}
+
+		var top, _ = stack.Pop()
+		n = top.(*node)
+
+		if !fn(n.key, n.value) {
+			return
+		}
+
+		n = n.right
+	}
+}
+
+// PreOrder visits each node before its children.
+// It walks iteratively, using a linked stack of nodes still to visit.
+func (t *Tree) PreOrder(fn tree.Visit) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.root == nil {
+		return
+	}
+
+	var stack = linkedstack.New(t.root)
+
+	for !stack.IsEmpty() {
+		var top, _ = stack.Pop()
+		var n = top.(*node)
+
+		if !fn(n.key, n.value) {
+			return
+		}
+
+		// Right first, so left comes off the stack first
+		if n.right != nil {
+			stack.Push(n.right)
+		}
+
+		if n.left != nil {
+			stack.Push(n.left)
+		}
+	}
+}
+
+// PostOrder visits each node after its children.
+// It walks iteratively with a linked stack. A node is only visited once
+// its right subtree is done, which we know because we just came from there.
+func (t *Tree) PostOrder(fn tree.Visit) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var stack = linkedstack.New()
+	var n = t.root
+	var last *node
+
+	for n != nil || !stack.IsEmpty() {
+		for ; n != nil; n = n.left {
+			stack.Push(n)
+		}
+
+		var top, _ = stack.Peek()
+		var peek = top.(*node)
+
+		// Right subtree still to do
+		if peek.right != nil && peek.right != last {
+			n = peek.right
+			continue
+		}
+
+		stack.Pop()
+
+		if !fn(peek.key, peek.value) {
+			return
+		}
+
+		last = peek
+	}
+}
+
+// LevelOrder visits nodes level by level from the root, left to right.
+// It uses a linked queue of nodes still to visit.
+func (t *Tree) LevelOrder(fn tree.Visit) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.root == nil {
+		return
+	}
+
+	var queue = linked.New()
+	queue.Enqueue(t.root)
+
+	for !queue.IsEmpty() {
+		var front, _ = queue.Dequeue()
+		var n = front.(*node)
+
+		if !fn(n.key, n.value) {
+			return
+		}
+
+		if n.left != nil {
+			queue.Enqueue(n.left)
+		}
+
+		if n.right != nil {
+			queue.Enqueue(n.right)
+		}
+	}
+}
+
+// Helper Methods
+// These methods are used internally.
+
+// find returns the node holding key, or nil
+func (t *Tree) find(key interface{}) *node {
+	var n = t.root
+
+	for n != nil {
+		var cmp = t.compare(key, n.key)
+
+		switch {
+		case cmp < 0:
+			n = n.left
+		case cmp > 0:
+			n = n.right
+		default:
+			return n
+		}
+	}
+
+	return nil
+}
+
+// height returns the height of the subtree at n
+func height(n *node) int {
+	if n == nil {
+		return 0
+	}
+
+	var l, r = height(n.left), height(n.right)
+	if l > r {
+		return l + 1
+	}
+
+	return r + 1
+}
@@ -0,0 +1,154 @@
+package bst
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/noriah/go-code/structure/tree"
+)
+
+func collect(walk func(tree.Visit)) string {
+	var keys []interface{}
+	walk(func(key, value interface{}) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return fmt.Sprint(keys)
+}
+
+func TestTraversals(t *testing.T) {
+	bst := New(tree.Ints)
+	for _, key := range []int{50, 30, 70, 20, 40, 60, 80} {
+		bst.Put(key, key*10)
+	}
+
+	tests := []struct {
+		name   string
+		walk   func(tree.Visit)
+		expect string
+	}{
+		{"InOrder", bst.InOrder, "[20 30 40 50 60 70 80]"},
+		{"PreOrder", bst.PreOrder, "[50 30 20 40 70 60 80]"},
+		{"PostOrder", bst.PostOrder, "[20 40 30 60 80 70 50]"},
+		{"LevelOrder", bst.LevelOrder, "[50 30 70 20 40 60 80]"},
+	}
+
+	for _, test := range tests {
+		if got := collect(test.walk); got != test.expect {
+			t.Errorf("%s: expected %s, got %s", test.name, test.expect, got)
+		}
+	}
+
+	// Stopping early
+	var count int
+	bst.InOrder(func(key, value interface{}) bool {
+		count++
+		return key.(int) < 40
+	})
+
+	if count != 3 {
+		t.Errorf("expected traversal to stop after %d keys, got %d", 3, count)
+	}
+}
+
+func TestOrderQueries(t *testing.T) {
+	bst := New(tree.Ints)
+
+	if _, _, err := bst.Min(); err != errorTreeEmpty {
+		t.Errorf("expected %v, got %v", errorTreeEmpty, err)
+	}
+
+	for _, key := range []int{50, 30, 70, 20, 40, 60, 80} {
+		bst.Put(key, nil)
+	}
+
+	if key, _, _ := bst.Min(); key != 20 {
+		t.Errorf("Min: expected %d, got %v", 20, key)
+	}
+
+	if key, _, _ := bst.Max(); key != 80 {
+		t.Errorf("Max: expected %d, got %v", 80, key)
+	}
+
+	floors := map[int]interface{}{10: nil, 20: 20, 45: 40, 65: 60, 99: 80}
+	for key, expect := range floors {
+		got, _, err := bst.Floor(key)
+		if expect == nil && err != errorKeyNotFound || expect != nil && got != expect {
+			t.Errorf("Floor(%d): expected %v, got %v (%v)", key, expect, got, err)
+		}
+	}
+
+	ceilings := map[int]interface{}{10: 20, 20: 20, 45: 50, 65: 70, 99: nil}
+	for key, expect := range ceilings {
+		got, _, err := bst.Ceiling(key)
+		if expect == nil && err != errorKeyNotFound || expect != nil && got != expect {
+			t.Errorf("Ceiling(%d): expected %v, got %v (%v)", key, expect, got, err)
+		}
+	}
+}
+
+func TestRandomOps(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	bst := New(tree.Ints)
+	shadow := make(map[int]int)
+
+	for i := 0; i < 5000; i++ {
+		key := rnd.Intn(500)
+
+		if rnd.Intn(3) == 0 {
+			_, had := shadow[key]
+			delete(shadow, key)
+
+			if err := bst.Delete(key); (err == nil) != had {
+				t.Fatalf("Delete(%d): had %t, got %v", key, had, err)
+			}
+			continue
+		}
+
+		shadow[key] = i
+		bst.Put(key, i)
+	}
+
+	if bst.Size() != len(shadow) {
+		t.Fatalf("expected size %d, got %d", len(shadow), bst.Size())
+	}
+
+	var keys []int
+	for key, value := range shadow {
+		keys = append(keys, key)
+
+		if got, err := bst.Get(key); err != nil || got != value {
+			t.Fatalf("Get(%d): expected %d, got %v (%v)", key, value, got, err)
+		}
+	}
+	sort.Ints(keys)
+
+	var got []int
+	bst.InOrder(func(key, value interface{}) bool {
+		got = append(got, key.(int))
+		return true
+	})
+
+	if fmt.Sprint(got) != fmt.Sprint(keys) {
+		t.Errorf("in order keys differ from shadow map")
+	}
+
+	bst.Clear()
+
+	if !bst.IsEmpty() || bst.Size() != 0 || bst.Height() != 0 {
+		t.Error("expected empty tree after Clear")
+	}
+}
+
+func TestSortedInsertIsUnbalanced(t *testing.T) {
+	bst := New(tree.Ints)
+	for i := 0; i < 100; i++ {
+		bst.Put(i, nil)
+	}
+
+	if height := bst.Height(); height != 100 {
+		t.Errorf("expected height %d, got %d", 100, height)
+	}
+}
@@ -0,0 +1,194 @@
+package btree
+
+import (
+	"sort"
+
+	"github.com/noriah/go-code/structure/tree"
+)
+
+// frame is a node on the path from the root to the cursor
+type frame struct {
+	n *node // Node on the path
+	i int   // Ascending: next item to visit. Descending: one past it
+}
+
+// Cursor walks the keys of a tree in order, one at a time.
+//
+// A cursor reads a snapshot taken when it was made. Writes to the tree
+// after that are not seen, and do not upset the cursor.
+type Cursor struct {
+	compare    tree.Compare // How the tree orders keys
+	stack      []frame      // Path from the root to the next item
+	descending bool         // Walk from big keys to small
+	key        interface{}  // Key of the current item
+	value      interface{}  // Value of the current item
+}
+
+// Ascend returns a cursor over the keys in ascending order.
+// The optional from may be specified, and the cursor starts at the first
+// key not smaller than it. Only the first value will be used.
+//
+// Time: O(log n)
+func (t *Tree) Ascend(from ...interface{}) *Cursor {
+	var c = t.cursor(false)
+
+	if len(from) > 0 {
+		c.seek(from[0])
+	} else {
+		c.edge()
+	}
+
+	return c
+}
+
+// Descend returns a cursor over the keys in descending order.
+// The optional from may be specified, and the cursor starts at the last
+// key not bigger than it. Only the first value will be used.
+//
+// Time: O(log n)
+func (t *Tree) Descend(from ...interface{}) *Cursor {
+	var c = t.cursor(true)
+
+	if len(from) > 0 {
+		c.seek(from[0])
+	} else {
+		c.edge()
+	}
+
+	return c
+}
+
+// Next moves the cursor to the next key.
+// Returns false when there are no more keys.
+//
+// Time: O(1) amortized
+func (c *Cursor) Next() bool {
+	for len(c.stack) > 0 {
+		var top = &c.stack[len(c.stack)-1]
+		var n = top.n
+
+		if c.descending && top.i > 0 {
+			top.i--
+			c.key, c.value = n.items[top.i].key, n.items[top.i].value
+
+			// Everything left of this item comes next
+			if !n.leaf() {
+				c.push(n.children[top.i])
+			}
+
+			return true
+		}
+
+		if !c.descending && top.i < len(n.items) {
+			c.key, c.value = n.items[top.i].key, n.items[top.i].value
+			top.i++
+
+			// Everything right of this item comes next
+			if !n.leaf() {
+				c.push(n.children[top.i])
+			}
+
+			return true
+		}
+
+		// Done with this node
+		c.stack = c.stack[:len(c.stack)-1]
+	}
+
+	c.key, c.value = nil, nil
+
+	return false
+}
+
+// Key returns the key the cursor is on
+func (c *Cursor) Key() interface{} {
+	return c.key
+}
+
+// Value returns the value the cursor is on
+func (c *Cursor) Value() interface{} {
+	return c.value
+}
+
+// Helper Methods
+// These methods are used internally.
+
+// cursor returns a cursor on a snapshot of the tree
+func (t *Tree) cursor(descending bool) *Cursor {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// From now on the tree copies nodes before changing them,
+	// leaving the ones we hold alone
+	t.gen = &generation{}
+
+	var c = &Cursor{compare: t.compare, descending: descending}
+
+	if t.root != nil {
+		c.stack = []frame{{n: t.root}}
+	}
+
+	return c
+}
+
+// edge starts the cursor at the first key in its direction
+func (c *Cursor) edge() {
+	if len(c.stack) == 0 {
+		return
+	}
+
+	var root = c.stack[0].n
+	c.stack = c.stack[:0]
+	c.push(root)
+}
+
+// push steps down from n to the first leaf in the cursor's direction
+func (c *Cursor) push(n *node) {
+	for {
+		var i = 0
+		if c.descending {
+			i = len(n.items)
+		}
+
+		c.stack = append(c.stack, frame{n, i})
+
+		if n.leaf() {
+			return
+		}
+
+		n = n.children[i]
+	}
+}
+
+// seek starts the cursor at key, or the first key past it in the
+// cursor's direction
+func (c *Cursor) seek(key interface{}) {
+	if len(c.stack) == 0 {
+		return
+	}
+
+	var n = c.stack[0].n
+	c.stack = c.stack[:0]
+
+	for {
+		// First item not smaller than key
+		var i = sort.Search(len(n.items), func(i int) bool {
+			return c.compare(n.items[i].key, key) >= 0
+		})
+
+		var found = i < len(n.items) && c.compare(n.items[i].key, key) == 0
+
+		if found && c.descending {
+			c.stack = append(c.stack, frame{n, i + 1})
+			return
+		}
+
+		c.stack = append(c.stack, frame{n, i})
+
+		if found || n.leaf() {
+			return
+		}
+
+		n = n.children[i]
+	}
+}
@@ -0,0 +1,649 @@
+// Package btree holds implementation for a B-Tree.
+// A B-Tree keeps many keys in each node instead of one. With a minimum
+// degree of d, every node but the root holds between d-1 and 2d-1 keys,
+// and every leaf is at the same depth. Wide nodes mean a short tree, fewer
+// pointers to chase, and keys that sit next to each other in memory.
+//
+// Nodes are copy-on-write. Clone and cursors share nodes with the tree, and
+// a write copies a shared node before changing it, so snapshots are O(1).
+package btree
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/noriah/go-code/structure/tree"
+)
+
+// The minimum degree used when none is given
+const defaultDegree = 32
+
+// An error to be returned when Min/Max-ing on an empty tree
+var errorTreeEmpty = errors.New("empty tree")
+
+// An error to be returned when a key is not in the tree
+var errorKeyNotFound = errors.New("key not found")
+
+// An error to be returned when Load-ing into a tree that has keys
+var errorTreeNotEmpty = errors.New("tree not empty")
+
+// An error to be returned when Load-ing keys that are not strictly ascending
+var errorNotSorted = errors.New("keys not sorted")
+
+// An error to be returned when Load-ing a different number of keys and values
+var errorLengthMismatch = errors.New("keys and values differ in length")
+
+// item is a key and its value
+type item struct {
+	key   interface{} // Key this item is sorted by
+	value interface{} // Value held for the key
+}
+
+// generation marks which tree owns a node. A tree only changes nodes
+// from its own generation, and copies the rest first.
+type generation struct {
+	_ byte // Not zero sized, so every generation has its own address
+}
+
+// node is a page of the tree
+type node struct {
+	items    []item      // Sorted items in this node
+	children []*node     // len(items)+1 subtrees. nil for leaves
+	gen      *generation // Generation that may change this node in place
+}
+
+// Tree implements a B-Tree
+type Tree struct {
+	mu      sync.Mutex   // Mutex for safe parallel operations
+	root    *node        // Root node of our tree. nil when empty
+	count   int          // Total number of items
+	degree  int          // Minimum degree. Nodes hold degree-1 to 2*degree-1 items
+	compare tree.Compare // How we order keys
+	gen     *generation  // Our generation. Nodes from any other are shared
+}
+
+// New returns a new B-Tree ordered by compare.
+// The optional minimum degree may be specified. Only the first value will be used.
+func New(compare tree.Compare, degree ...int) *Tree {
+	if compare == nil {
+		panic("nil compare provided")
+	}
+
+	var d = defaultDegree
+
+	if len(degree) > 0 {
+		d = degree[0]
+		if d < 2 {
+			panic("Value less than 2 for degree provided")
+		}
+	}
+
+	return &Tree{
+		degree:  d,
+		compare: compare,
+		gen:     &generation{},
+	}
+}
+
+// Size returns the number of keys in the tree
+func (t *Tree) Size() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.count
+}
+
+// IsEmpty checks for tree emptiness
+func (t *Tree) IsEmpty() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.count == 0
+}
+
+// Clear empties the tree
+func (t *Tree) Clear() {
+	t.mu.Lock()
+
+	t.root = nil
+	t.count = 0
+
+	t.mu.Unlock()
+}
+
+// Clone returns a copy of the tree.
+// The copy shares every node with the tree until one of them writes to it.
+//
+// Time: O(1)
+func (t *Tree) Clone() *Tree {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Neither tree may change the shared nodes in place any more
+	t.gen = &generation{}
+
+	return &Tree{
+		root:    t.root,
+		count:   t.count,
+		degree:  t.degree,
+		compare: t.compare,
+		gen:     &generation{},
+	}
+}
+
+// Put sets the value for key, adding the key if it is new.
+//
+// Time: O(d log n)
+func (t *Tree) Put(key, value interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.root == nil {
+		t.root = &node{items: []item{{key, value}}, gen: t.gen}
+		t.count++
+		return
+	}
+
+	t.root = t.mutable(t.root)
+
+	// Split a full root first, so there is room for a key to come up.
+	// This is the only way the tree grows taller.
+	if len(t.root.items) >= t.maxItems() {
+		var mid, right = t.split(t.root, t.degree-1)
+		t.root = &node{
+			items:    []item{mid},
+			children: []*node{t.root, right},
+			gen:      t.gen,
+		}
+	}
+
+	if t.insert(t.root, item{key, value}) {
+		t.count++
+	}
+}
+
+// Get returns the value for key.
+// Returns nil and error if the key is not in the tree.
+//
+// Time: O(log n)
+func (t *Tree) Get(key interface{}) (interface{}, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for n := t.root; n != nil; {
+		var i, found = t.find(n, key)
+		if found {
+			return n.items[i].value, nil
+		}
+
+		if n.leaf() {
+			break
+		}
+
+		n = n.children[i]
+	}
+
+	return nil, errorKeyNotFound
+}
+
+// Has returns true if key is in the tree
+func (t *Tree) Has(key interface{}) bool {
+	var _, err = t.Get(key)
+
+	return err == nil
+}
+
+// Delete removes key from the tree.
+// Returns error if the key is not in the tree.
+//
+// Time: O(d log n)
+func (t *Tree) Delete(key interface{}) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.root == nil {
+		return errorKeyNotFound
+	}
+
+	t.root = t.mutable(t.root)
+
+	var found = t.remove(t.root, key)
+
+	// A merge can empty the root. This is the only way the tree shrinks
+	if len(t.root.items) == 0 {
+		if t.root.leaf() {
+			t.root = nil
+		} else {
+			t.root = t.root.children[0]
+		}
+	}
+
+	if !found {
+		return errorKeyNotFound
+	}
+
+	t.count--
+
+	return nil
+}
+
+// Min returns the smallest key and its value
+func (t *Tree) Min() (key, value interface{}, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.root == nil {
+		return nil, nil, errorTreeEmpty
+	}
+
+	var n = t.root
+	for !n.leaf() {
+		n = n.children[0]
+	}
+
+	return n.items[0].key, n.items[0].value, nil
+}
+
+// Max returns the biggest key and its value
+func (t *Tree) Max() (key, value interface{}, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.root == nil {
+		return nil, nil, errorTreeEmpty
+	}
+
+	var n = t.root
+	for !n.leaf() {
+		n = n.children[len(n.children)-1]
+	}
+
+	var last = n.items[len(n.items)-1]
+
+	return last.key, last.value, nil
+}
+
+// InOrder visits every key in ascending order.
+// The walk runs on a snapshot, so fn may change the tree.
+//
+// Time: O(n)
+func (t *Tree) InOrder(fn tree.Visit) {
+	for c := t.Ascend(); c.Next(); {
+		if !fn(c.Key(), c.Value()) {
+			return
+		}
+	}
+}
+
+// Range visits every key in [from, to] in ascending order.
+// The walk runs on a snapshot, so fn may change the tree.
+//
+// Time: O(log n + m) where m is the number of keys visited
+func (t *Tree) Range(from, to interface{}, fn tree.Visit) {
+	for c := t.Ascend(from); c.Next(); {
+		if t.compare(c.Key(), to) > 0 || !fn(c.Key(), c.Value()) {
+			return
+		}
+	}
+}
+
+// Load fills an empty tree from keys in strictly ascending order.
+// values may be nil, otherwise it must be as long as keys.
+// The tree is built bottom up with every node as full as it can be,
+// which is faster than Put-ing each key and packs the nodes tighter.
+//
+// Time: O(n)
+func (t *Tree) Load(keys, values []interface{}) error {
+	if values != nil && len(values) != len(keys) {
+		return errorLengthMismatch
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.count > 0 {
+		return errorTreeNotEmpty
+	}
+
+	var items = make([]item, len(keys))
+
+	for i, key := range keys {
+		if i > 0 && t.compare(keys[i-1], key) >= 0 {
+			return errorNotSorted
+		}
+
+		items[i].key = key
+		if values != nil {
+			items[i].value = values[i]
+		}
+	}
+
+	if len(items) == 0 {
+		return nil
+	}
+
+	// Find the shortest tree that fits. A full tree of height h
+	// holds (2d)^h - 1 items
+	var height, span = 1, 2 * t.degree
+	for span-1 < len(items) {
+		height++
+		span *= 2 * t.degree
+	}
+
+	t.root = t.build(items, height, true)
+	t.count = len(items)
+
+	return nil
+}
+
+// Helper Methods
+// These methods are used internally.
+
+// maxItems is the most items a node may hold
+func (t *Tree) maxItems() int {
+	return 2*t.degree - 1
+}
+
+// minItems is the fewest items a node other than the root may hold
+func (t *Tree) minItems() int {
+	return t.degree - 1
+}
+
+// leaf returns true if n has no children
+func (n *node) leaf() bool {
+	return n.children == nil
+}
+
+// find returns the index of the first item in n not smaller than key,
+// and whether that item is key
+func (t *Tree) find(n *node, key interface{}) (int, bool) {
+	var i = sort.Search(len(n.items), func(i int) bool {
+		return t.compare(n.items[i].key, key) >= 0
+	})
+
+	return i, i < len(n.items) && t.compare(n.items[i].key, key) == 0
+}
+
+// mutable returns n if we own it, or our own copy of it
+func (t *Tree) mutable(n *node) *node {
+	if n.gen == t.gen {
+		return n
+	}
+
+	var c = &node{
+		items: make([]item, len(n.items), t.maxItems()),
+		gen:   t.gen,
+	}
+
+	copy(c.items, n.items)
+
+	if !n.leaf() {
+		c.children = make([]*node, len(n.children), t.maxItems()+1)
+		copy(c.children, n.children)
+	}
+
+	return c
+}
+
+// mutableChild makes n's i-th child one we own, and returns it.
+// n must already be ours.
+func (t *Tree) mutableChild(n *node, i int) *node {
+	var c = t.mutable(n.children[i])
+	n.children[i] = c
+
+	return c
+}
+
+// split cuts n at item i. n keeps what is left of i, and the item and a new
+// node with what is right of it are returned.
+func (t *Tree) split(n *node, i int) (item, *node) {
+	var mid = n.items[i]
+
+	var right = &node{
+		items: make([]item, 0, t.maxItems()),
+		gen:   t.gen,
+	}
+
+	right.items = append(right.items, n.items[i+1:]...)
+	n.items = truncateItems(n.items, i)
+
+	if !n.leaf() {
+		right.children = make([]*node, 0, t.maxItems()+1)
+		right.children = append(right.children, n.children[i+1:]...)
+		n.children = truncateChildren(n.children, i+1)
+	}
+
+	return mid, right
+}
+
+// insert adds it under n, which must be ours and not full.
+// Returns false if the key was already there and only the value changed.
+func (t *Tree) insert(n *node, it item) bool {
+	var i, found = t.find(n, it.key)
+
+	if found {
+		n.items[i].value = it.value
+		return false
+	}
+
+	if n.leaf() {
+		n.items = insertItem(n.items, i, it)
+		return true
+	}
+
+	// Split a full child on the way down, so it has room when we get there
+	if len(n.children[i].items) >= t.maxItems() {
+		var mid, right = t.split(t.mutableChild(n, i), t.degree-1)
+
+		n.items = insertItem(n.items, i, mid)
+		n.children = insertChild(n.children, i+1, right)
+
+		switch cmp := t.compare(it.key, mid.key); {
+		case cmp == 0:
+			n.items[i].value = it.value
+			return false
+		case cmp > 0:
+			i++
+		}
+	}
+
+	return t.insert(t.mutableChild(n, i), it)
+}
+
+// remove deletes key under n, which must be ours. Every child we step into
+// gets more than the minimum number of items first, so taking one away
+// from it never leaves it short. Returns false if key was not there.
+func (t *Tree) remove(n *node, key interface{}) bool {
+	var i, found = t.find(n, key)
+
+	if n.leaf() {
+		if found {
+			n.items = removeItem(n.items, i)
+		}
+
+		return found
+	}
+
+	if len(n.children[i].items) <= t.minItems() {
+		t.grow(n, i)
+
+		// The items moved around. Look again
+		return t.remove(n, key)
+	}
+
+	var child = t.mutableChild(n, i)
+
+	if found {
+		// Replace it with its predecessor, the biggest item to its left
+		n.items[i] = t.removeMax(child)
+		return true
+	}
+
+	return t.remove(child, key)
+}
+
+// removeMax deletes and returns the biggest item under n, which must be ours
+func (t *Tree) removeMax(n *node) item {
+	if n.leaf() {
+		var last = n.items[len(n.items)-1]
+		n.items = truncateItems(n.items, len(n.items)-1)
+
+		return last
+	}
+
+	if len(n.children[len(n.items)].items) <= t.minItems() {
+		t.grow(n, len(n.items))
+	}
+
+	return t.removeMax(t.mutableChild(n, len(n.items)))
+}
+
+// grow gives n's i-th child more than the minimum number of items, by
+// taking one from a sibling or by merging it with one
+func (t *Tree) grow(n *node, i int) {
+	switch {
+	// Rotate one through n from the left sibling
+	case i > 0 && len(n.children[i-1].items) > t.minItems():
+		var child, left = t.mutableChild(n, i), t.mutableChild(n, i-1)
+
+		child.items = insertItem(child.items, 0, n.items[i-1])
+		n.items[i-1] = left.items[len(left.items)-1]
+		left.items = truncateItems(left.items, len(left.items)-1)
+
+		if !left.leaf() {
+			child.children = insertChild(child.children, 0, left.children[len(left.children)-1])
+			left.children = truncateChildren(left.children, len(left.children)-1)
+		}
+
+	// Rotate one through n from the right sibling
+	case i < len(n.items) && len(n.children[i+1].items) > t.minItems():
+		var child, right = t.mutableChild(n, i), t.mutableChild(n, i+1)
+
+		child.items = append(child.items, n.items[i])
+		n.items[i] = right.items[0]
+		right.items = removeItem(right.items, 0)
+
+		if !right.leaf() {
+			child.children = append(child.children, right.children[0])
+			right.children = removeChild(right.children, 0)
+		}
+
+	// Both siblings are at the minimum. Merge with one, pulling the item
+	// between them down from n
+	default:
+		if i == len(n.items) {
+			i--
+		}
+
+		var child, sibling = t.mutableChild(n, i), n.children[i+1]
+
+		child.items = append(child.items, n.items[i])
+		child.items = append(child.items, sibling.items...)
+		child.children = append(child.children, sibling.children...)
+
+		n.items = removeItem(n.items, i)
+		n.children = removeChild(n.children, i+1)
+	}
+}
+
+// build makes a subtree of the given height from sorted items.
+// The items are spread as evenly as they can be, which keeps every node
+// between the minimum and maximum size.
+func (t *Tree) build(items []item, height int, root bool) *node {
+	var n = &node{gen: t.gen}
+
+	if height == 1 {
+		n.items = make([]item, len(items), t.maxItems())
+		copy(n.items, items)
+
+		return n
+	}
+
+	// Each child, plus the item after it, takes up a slot. A full
+	// child of height-1 takes up span slots.
+	var span = 1
+	for h := 1; h < height; h++ {
+		span *= 2 * t.degree
+	}
+
+	var slots = len(items) + 1
+	var k = (slots + span - 1) / span
+
+	if !root && k < t.degree {
+		k = t.degree
+	}
+
+	n.items = make([]item, 0, t.maxItems())
+	n.children = make([]*node, 0, t.maxItems()+1)
+
+	var start = 0
+	for c := 0; c < k; c++ {
+		var take = slots / k
+		if c < slots%k {
+			take++
+		}
+
+		// take-1 items for the child, and one to sit after it
+		var end = start + take - 1
+		n.children = append(n.children, t.build(items[start:end], height-1, false))
+
+		if c < k-1 {
+			n.items = append(n.items, items[end])
+		}
+
+		start = end + 1
+	}
+
+	return n
+}
+
+// insertItem puts it at index i of items
+func insertItem(items []item, i int, it item) []item {
+	items = append(items, item{})
+	copy(items[i+1:], items[i:])
+	items[i] = it
+
+	return items
+}
+
+// removeItem takes out index i of items
+func removeItem(items []item, i int) []item {
+	copy(items[i:], items[i+1:])
+
+	return truncateItems(items, len(items)-1)
+}
+
+// truncateItems cuts items to length i, dropping references past the end
+func truncateItems(items []item, i int) []item {
+	for j := i; j < len(items); j++ {
+		items[j] = item{}
+	}
+
+	return items[:i]
+}
+
+// insertChild puts c at index i of children
+func insertChild(children []*node, i int, c *node) []*node {
+	children = append(children, nil)
+	copy(children[i+1:], children[i:])
+	children[i] = c
+
+	return children
+}
+
+// removeChild takes out index i of children
+func removeChild(children []*node, i int) []*node {
+	copy(children[i:], children[i+1:])
+
+	return truncateChildren(children, len(children)-1)
+}
+
+// truncateChildren cuts children to length i, dropping references past the end
+func truncateChildren(children []*node, i int) []*node {
+	for j := i; j < len(children); j++ {
+		children[j] = nil
+	}
+
+	return children[:i]
+}
@@ -0,0 +1,344 @@
+package btree
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/noriah/go-code/structure/tree"
+)
+
+// checkInvariants fails the test if any node is out of order, too full or
+// too empty, or if the leaves are not all at the same depth
+func checkInvariants(t *testing.T, bt *Tree) {
+	t.Helper()
+
+	var leafDepth = -1
+	var count int
+
+	var check func(n *node, depth int, lo, hi interface{})
+	check = func(n *node, depth int, lo, hi interface{}) {
+		if n != bt.root && len(n.items) < bt.minItems() || len(n.items) > bt.maxItems() || len(n.items) == 0 {
+			t.Fatalf("node at depth %d holds %d items, expected %d to %d",
+				depth, len(n.items), bt.minItems(), bt.maxItems())
+		}
+
+		for i, it := range n.items {
+			if i > 0 && bt.compare(n.items[i-1].key, it.key) >= 0 ||
+				lo != nil && bt.compare(it.key, lo) <= 0 ||
+				hi != nil && bt.compare(it.key, hi) >= 0 {
+				t.Fatalf("key %v out of order", it.key)
+			}
+		}
+
+		count += len(n.items)
+
+		if n.leaf() {
+			if leafDepth == -1 {
+				leafDepth = depth
+			} else if depth != leafDepth {
+				t.Fatalf("leaves at depths %d and %d", leafDepth, depth)
+			}
+
+			return
+		}
+
+		if len(n.children) != len(n.items)+1 {
+			t.Fatalf("node with %d items has %d children", len(n.items), len(n.children))
+		}
+
+		for i, child := range n.children {
+			var clo, chi = lo, hi
+			if i > 0 {
+				clo = n.items[i-1].key
+			}
+
+			if i < len(n.items) {
+				chi = n.items[i].key
+			}
+
+			check(child, depth+1, clo, chi)
+		}
+	}
+
+	if bt.root != nil {
+		check(bt.root, 0, nil, nil)
+	}
+
+	if count != bt.count {
+		t.Fatalf("expected %d items, counted %d", bt.count, count)
+	}
+}
+
+// keys returns every key in the tree, in the cursor's order
+func keys(c *Cursor) string {
+	var out []interface{}
+	for c.Next() {
+		out = append(out, c.Key())
+	}
+
+	return fmt.Sprint(out)
+}
+
+func TestRandomized(t *testing.T) {
+	const count = 2000
+
+	for _, degree := range []int{2, 3, 8} {
+		bt := New(tree.Ints, degree)
+		expect := map[int]int{}
+		rng := rand.New(rand.NewSource(int64(degree)))
+
+		for i := 0; i < 4*count; i++ {
+			key := rng.Intn(count)
+
+			if rng.Intn(3) == 0 {
+				err := bt.Delete(key)
+				if _, ok := expect[key]; ok != (err == nil) {
+					t.Fatalf("degree %d: Delete(%d): key present %t, got error %v", degree, key, ok, err)
+				}
+
+				delete(expect, key)
+			} else {
+				bt.Put(key, i)
+				expect[key] = i
+			}
+
+			if i%50 == 0 {
+				checkInvariants(t, bt)
+			}
+		}
+
+		checkInvariants(t, bt)
+
+		if bt.Size() != len(expect) {
+			t.Fatalf("degree %d: expected size %d, got %d", degree, len(expect), bt.Size())
+		}
+
+		for key, value := range expect {
+			if got, err := bt.Get(key); err != nil || got != value {
+				t.Errorf("degree %d: Get(%d): expected %d, got %v (%v)", degree, key, value, got, err)
+			}
+		}
+
+		// Drain it completely
+		for key := range expect {
+			if err := bt.Delete(key); err != nil {
+				t.Fatalf("degree %d: Delete(%d): %v", degree, key, err)
+			}
+		}
+
+		checkInvariants(t, bt)
+
+		if !bt.IsEmpty() {
+			t.Errorf("degree %d: expected empty tree, got %d keys", degree, bt.Size())
+		}
+	}
+}
+
+func TestCursors(t *testing.T) {
+	bt := New(tree.Ints, 2)
+
+	if got := keys(bt.Ascend()); got != "[]" {
+		t.Errorf("empty Ascend: expected [], got %s", got)
+	}
+
+	for _, key := range rand.New(rand.NewSource(1)).Perm(10) {
+		bt.Put(key*10, nil)
+	}
+
+	tests := []struct {
+		name   string
+		cursor *Cursor
+		expect string
+	}{
+		{"Ascend", bt.Ascend(), "[0 10 20 30 40 50 60 70 80 90]"},
+		{"Ascend(40)", bt.Ascend(40), "[40 50 60 70 80 90]"},
+		{"Ascend(45)", bt.Ascend(45), "[50 60 70 80 90]"},
+		{"Ascend(95)", bt.Ascend(95), "[]"},
+		{"Descend", bt.Descend(), "[90 80 70 60 50 40 30 20 10 0]"},
+		{"Descend(40)", bt.Descend(40), "[40 30 20 10 0]"},
+		{"Descend(45)", bt.Descend(45), "[40 30 20 10 0]"},
+		{"Descend(-5)", bt.Descend(-5), "[]"},
+	}
+
+	for _, test := range tests {
+		if got := keys(test.cursor); got != test.expect {
+			t.Errorf("%s: expected %s, got %s", test.name, test.expect, got)
+		}
+	}
+
+	var got []interface{}
+	bt.Range(25, 65, func(key, value interface{}) bool {
+		got = append(got, key)
+		return true
+	})
+
+	if fmt.Sprint(got) != "[30 40 50 60]" {
+		t.Errorf("Range(25, 65): expected [30 40 50 60], got %v", got)
+	}
+
+	// A cursor keeps its snapshot while the tree changes under it
+	c := bt.Ascend()
+	for key := 0; key < 100; key += 10 {
+		bt.Delete(key)
+		bt.Put(key+5, nil)
+	}
+
+	checkInvariants(t, bt)
+
+	if got := keys(c); got != "[0 10 20 30 40 50 60 70 80 90]" {
+		t.Errorf("snapshot cursor: expected the old keys, got %s", got)
+	}
+
+	if got := keys(bt.Ascend()); got != "[5 15 25 35 45 55 65 75 85 95]" {
+		t.Errorf("Ascend after changes: expected the new keys, got %s", got)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	for degree := 2; degree <= 5; degree++ {
+		for n := 0; n < 300; n++ {
+			bt := New(tree.Ints, degree)
+
+			var keys, values []interface{}
+			for i := 0; i < n; i++ {
+				keys = append(keys, i)
+				values = append(values, -i)
+			}
+
+			if err := bt.Load(keys, values); err != nil {
+				t.Fatalf("degree %d, %d keys: %v", degree, n, err)
+			}
+
+			checkInvariants(t, bt)
+
+			if n > 0 {
+				if value, err := bt.Get(n - 1); err != nil || value != 1-n {
+					t.Fatalf("degree %d: Get(%d): expected %d, got %v (%v)", degree, n-1, 1-n, value, err)
+				}
+			}
+		}
+	}
+
+	bt := New(tree.Ints)
+
+	if err := bt.Load([]interface{}{1, 3, 2}, nil); err != errorNotSorted {
+		t.Errorf("expected %v, got %v", errorNotSorted, err)
+	}
+
+	if err := bt.Load([]interface{}{1, 1}, nil); err != errorNotSorted {
+		t.Errorf("expected %v, got %v", errorNotSorted, err)
+	}
+
+	if err := bt.Load([]interface{}{1, 2}, []interface{}{1}); err != errorLengthMismatch {
+		t.Errorf("expected %v, got %v", errorLengthMismatch, err)
+	}
+
+	bt.Put(1, nil)
+	if err := bt.Load([]interface{}{2}, nil); err != errorTreeNotEmpty {
+		t.Errorf("expected %v, got %v", errorTreeNotEmpty, err)
+	}
+}
+
+func TestClone(t *testing.T) {
+	bt := New(tree.Ints, 2)
+	for i := 0; i < 500; i++ {
+		bt.Put(i, "original")
+	}
+
+	clone := bt.Clone()
+	rng := rand.New(rand.NewSource(3))
+
+	// Change both sides. Neither should see the other
+	for _, key := range rng.Perm(500)[:250] {
+		clone.Delete(key)
+	}
+
+	for i := 500; i < 600; i++ {
+		clone.Put(i, "clone")
+	}
+
+	for i := 0; i < 500; i += 2 {
+		bt.Put(i, "changed")
+	}
+
+	checkInvariants(t, bt)
+	checkInvariants(t, clone)
+
+	if bt.Size() != 500 || clone.Size() != 350 {
+		t.Fatalf("expected sizes %d and %d, got %d and %d", 500, 350, bt.Size(), clone.Size())
+	}
+
+	for i := 0; i < 500; i++ {
+		expect := "original"
+		if i%2 == 0 {
+			expect = "changed"
+		}
+
+		if value, _ := bt.Get(i); value != expect {
+			t.Fatalf("original Get(%d): expected %s, got %v", i, expect, value)
+		}
+
+		if value, err := clone.Get(i); err == nil && value != "original" {
+			t.Fatalf("clone Get(%d): expected original, got %v", i, value)
+		}
+	}
+}
+
+func TestOrderQueries(t *testing.T) {
+	bt := New(tree.Strings, 2)
+
+	if _, _, err := bt.Min(); err != errorTreeEmpty {
+		t.Errorf("expected %v, got %v", errorTreeEmpty, err)
+	}
+
+	words := []string{"pear", "apple", "fig", "kiwi", "banana", "cherry", "date"}
+	for _, word := range words {
+		bt.Put(word, len(word))
+	}
+
+	sort.Strings(words)
+
+	if key, _, _ := bt.Min(); key != words[0] {
+		t.Errorf("Min: expected %s, got %v", words[0], key)
+	}
+
+	if key, value, _ := bt.Max(); key != "pear" || value != 4 {
+		t.Errorf("Max: expected pear 4, got %v %v", key, value)
+	}
+
+	var got []string
+	bt.InOrder(func(key, value interface{}) bool {
+		got = append(got, key.(string))
+		return len(got) < 3
+	})
+
+	if fmt.Sprint(got) != fmt.Sprint(words[:3]) {
+		t.Errorf("InOrder: expected %v, got %v", words[:3], got)
+	}
+
+	if bt.Has("grape") || !bt.Has("kiwi") {
+		t.Errorf("Has: wrong answer for grape or kiwi")
+	}
+}
+
+func BenchmarkPut(b *testing.B) {
+	keys := rand.New(rand.NewSource(1)).Perm(b.N)
+	bt := New(tree.Ints)
+
+	b.ResetTimer()
+	for _, key := range keys {
+		bt.Put(key, nil)
+	}
+}
+
+func BenchmarkLoad(b *testing.B) {
+	var keys []interface{}
+	for i := 0; i < b.N; i++ {
+		keys = append(keys, i)
+	}
+
+	b.ResetTimer()
+	New(tree.Ints).Load(keys, nil)
+}
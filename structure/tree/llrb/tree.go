@@ -0,0 +1,477 @@
+// Package llrb holds implementation for a Left-Leaning Red-Black Tree.
+// A red-black tree colours its links red or black. Every path from the root
+// to a leaf crosses the same number of black links, and no path has two red
+// links in a row, so no path is more than twice as long as any other.
+//
+// Left-leaning means a red link only ever points left. This makes the tree
+// a 1-1 match for a 2-3 tree, and cuts the number of fix-up cases down to
+// three rotations and a colour flip (Sedgewick, 2008).
+//
+// Each node also keeps the size of its subtree, which gives Rank and
+// Select in O(log n).
+package llrb
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/noriah/go-code/structure/tree"
+)
+
+// An error to be returned when Min/Max-ing on an empty tree
+var errorTreeEmpty = errors.New("empty tree")
+
+// An error to be returned when a key is not in the tree
+var errorKeyNotFound = errors.New("key not found")
+
+// An error to be returned when Select-ing past the end of the tree
+var errorOutOfRange = errors.New("index out of range")
+
+// Colours of the link from a node's parent to the node
+const (
+	red   = true
+	black = false
+)
+
+// node is an entry in the tree
+type node struct {
+	left  *node       // Subtree of smaller keys
+	right *node       // Subtree of bigger keys
+	key   interface{} // Key this node is sorted by
+	value interface{} // Value held for the key
+	color bool        // Colour of the link from our parent
+	size  int         // Number of nodes in the subtree rooted here
+}
+
+// Tree implements a Left-Leaning Red-Black Tree
+type Tree struct {
+	mu      sync.Mutex   // Mutex for safe parallel operations
+	root    *node        // Root node of our tree. nil when empty
+	compare tree.Compare // How we order keys
+}
+
+// New returns a new Left-Leaning Red-Black Tree ordered by compare
+func New(compare tree.Compare) *Tree {
+	if compare == nil {
+		panic("nil compare provided")
+	}
+
+	return &Tree{compare: compare}
+}
+
+// Size returns the number of keys in the tree
+func (t *Tree) Size() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return size(t.root)
+}
+
+// IsEmpty checks for tree emptiness
+func (t *Tree) IsEmpty() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.root == nil
+}
+
+// Clear empties the tree
+func (t *Tree) Clear() {
+	t.mu.Lock()
+
+	t.root = nil
+
+	t.mu.Unlock()
+}
+
+// Put sets the value for key, adding the key if it is new.
+//
+// Time: O(log n)
+func (t *Tree) Put(key, value interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.root = t.put(t.root, key, value)
+	t.root.color = black
+}
+
+// Get returns the value for key.
+// Returns nil and error if the key is not in the tree.
+//
+// Time: O(log n)
+func (t *Tree) Get(key interface{}) (interface{}, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if n := t.get(t.root, key); n != nil {
+		return n.value, nil
+	}
+
+	return nil, errorKeyNotFound
+}
+
+// Has returns true if key is in the tree
+func (t *Tree) Has(key interface{}) bool {
+	var _, err = t.Get(key)
+
+	return err == nil
+}
+
+// Delete removes key from the tree.
+// Returns error if the key is not in the tree.
+//
+// Time: O(log n)
+func (t *Tree) Delete(key interface{}) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// The top-down delete assumes the key is there
+	if t.get(t.root, key) == nil {
+		return errorKeyNotFound
+	}
+
+	// Let the root borrow from its children on the way down
+	if !isRed(t.root.left) && !isRed(t.root.right) {
+		t.root.color = red
+	}
+
+	t.root = t.delete(t.root, key)
+	if t.root != nil {
+		t.root.color = black
+	}
+
+	return nil
+}
+
+// Min returns the smallest key and its value
+func (t *Tree) Min() (key, value interface{}, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.root == nil {
+		return nil, nil, errorTreeEmpty
+	}
+
+	var n = min(t.root)
+
+	return n.key, n.value, nil
+}
+
+// Max returns the biggest key and its value
+func (t *Tree) Max() (key, value interface{}, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.root == nil {
+		return nil, nil, errorTreeEmpty
+	}
+
+	var n = t.root
+	for n.right != nil {
+		n = n.right
+	}
+
+	return n.key, n.value, nil
+}
+
+// Range visits every key in [from, to] in ascending order.
+// The tree is locked for the whole walk. fn must not call back into the tree.
+//
+// Time: O(log n + m) where m is the number of keys visited
+func (t *Tree) Range(from, to interface{}, fn tree.Visit) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.walk(t.root, from, to, fn)
+}
+
+// Rank returns the number of keys smaller than key.
+// key does not have to be in the tree.
+//
+// Time: O(log n)
+func (t *Tree) Rank(key interface{}) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var rank int
+
+	for n := t.root; n != nil; {
+		var cmp = t.compare(key, n.key)
+
+		switch {
+		case cmp < 0:
+			n = n.left
+		case cmp > 0:
+			// Everything on the left, and n itself, is smaller
+			rank += size(n.left) + 1
+			n = n.right
+		default:
+			return rank + size(n.left)
+		}
+	}
+
+	return rank
+}
+
+// Select returns the key with rank i, the i-th smallest counting from 0,
+// and its value.
+//
+// Time: O(log n)
+func (t *Tree) Select(i int) (key, value interface{}, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if i < 0 || i >= size(t.root) {
+		return nil, nil, errorOutOfRange
+	}
+
+	var n = t.root
+
+	for {
+		var left = size(n.left)
+
+		switch {
+		case i < left:
+			n = n.left
+		case i > left:
+			i -= left + 1
+			n = n.right
+		default:
+			return n.key, n.value, nil
+		}
+	}
+}
+
+// Helper Methods
+// These methods are used internally.
+
+// get returns the node holding key, or nil
+func (t *Tree) get(n *node, key interface{}) *node {
+	for n != nil {
+		var cmp = t.compare(key, n.key)
+
+		switch {
+		case cmp < 0:
+			n = n.left
+		case cmp > 0:
+			n = n.right
+		default:
+			return n
+		}
+	}
+
+	return nil
+}
+
+// put adds key under n and returns the new subtree root
+func (t *Tree) put(n *node, key, value interface{}) *node {
+	// New keys always hang off a red link
+	if n == nil {
+		return &node{key: key, value: value, color: red, size: 1}
+	}
+
+	var cmp = t.compare(key, n.key)
+
+	switch {
+	case cmp < 0:
+		n.left = t.put(n.left, key, value)
+	case cmp > 0:
+		n.right = t.put(n.right, key, value)
+	default:
+		n.value = value
+	}
+
+	return fixUp(n)
+}
+
+// delete removes key, which must be under n, and returns the new subtree
+// root. On the way down it makes sure the node we step into is not a
+// 2-node, so the key can be removed from the bottom without breaking the
+// black height.
+func (t *Tree) delete(n *node, key interface{}) *node {
+	if t.compare(key, n.key) < 0 {
+		if !isRed(n.left) && !isRed(n.left.left) {
+			n = moveRedLeft(n)
+		}
+
+		n.left = t.delete(n.left, key)
+
+		return fixUp(n)
+	}
+
+	if isRed(n.left) {
+		n = rotateRight(n)
+	}
+
+	// Found at the bottom. Red, so it can just go
+	if t.compare(key, n.key) == 0 && n.right == nil {
+		return nil
+	}
+
+	if !isRed(n.right) && !isRed(n.right.left) {
+		n = moveRedRight(n)
+	}
+
+	if t.compare(key, n.key) == 0 {
+		// Swap in the successor, then remove it from the right
+		var succ = min(n.right)
+		n.key, n.value = succ.key, succ.value
+		n.right = deleteMin(n.right)
+	} else {
+		n.right = t.delete(n.right, key)
+	}
+
+	return fixUp(n)
+}
+
+// walk visits keys in [from, to] under n. Returns false to stop
+func (t *Tree) walk(n *node, from, to interface{}, fn tree.Visit) bool {
+	if n == nil {
+		return true
+	}
+
+	var cmpFrom, cmpTo = t.compare(from, n.key), t.compare(to, n.key)
+
+	// Smaller keys might be in range
+	if cmpFrom < 0 && !t.walk(n.left, from, to, fn) {
+		return false
+	}
+
+	if cmpFrom <= 0 && cmpTo >= 0 && !fn(n.key, n.value) {
+		return false
+	}
+
+	// Bigger keys might be in range
+	if cmpTo > 0 {
+		return t.walk(n.right, from, to, fn)
+	}
+
+	return true
+}
+
+// deleteMin removes the smallest node under n and returns the new root
+func deleteMin(n *node) *node {
+	if n.left == nil {
+		return nil
+	}
+
+	if !isRed(n.left) && !isRed(n.left.left) {
+		n = moveRedLeft(n)
+	}
+
+	n.left = deleteMin(n.left)
+
+	return fixUp(n)
+}
+
+// min returns the smallest node under n
+func min(n *node) *node {
+	for n.left != nil {
+		n = n.left
+	}
+
+	return n
+}
+
+// fixUp restores the left-leaning shape on the way back up, and
+// recomputes n's size
+func fixUp(n *node) *node {
+	// Right-leaning red link. Lean it left
+	if isRed(n.right) && !isRed(n.left) {
+		n = rotateLeft(n)
+	}
+
+	// Two reds in a row on the left. Balance them
+	if isRed(n.left) && isRed(n.left.left) {
+		n = rotateRight(n)
+	}
+
+	// Both children red. Split the 4-node
+	if isRed(n.left) && isRed(n.right) {
+		flipColors(n)
+	}
+
+	n.size = size(n.left) + size(n.right) + 1
+
+	return n
+}
+
+// moveRedLeft makes n.left or one of its children red, assuming n is red
+// and both n.left and n.left.left are black
+func moveRedLeft(n *node) *node {
+	flipColors(n)
+
+	if isRed(n.right.left) {
+		n.right = rotateRight(n.right)
+		n = rotateLeft(n)
+		flipColors(n)
+	}
+
+	return n
+}
+
+// moveRedRight makes n.right or one of its children red, assuming n is red
+// and both n.right and n.right.left are black
+func moveRedRight(n *node) *node {
+	flipColors(n)
+
+	if isRed(n.left.left) {
+		n = rotateRight(n)
+		flipColors(n)
+	}
+
+	return n
+}
+
+// rotateLeft lifts n's right child above it, keeping n's link colour
+func rotateLeft(n *node) *node {
+	var r = n.right
+
+	n.right = r.left
+	r.left = n
+
+	r.color = n.color
+	n.color = red
+
+	r.size = n.size
+	n.size = size(n.left) + size(n.right) + 1
+
+	return r
+}
+
+// rotateRight lifts n's left child above it, keeping n's link colour
+func rotateRight(n *node) *node {
+	var l = n.left
+
+	n.left = l.right
+	l.right = n
+
+	l.color = n.color
+	n.color = red
+
+	l.size = n.size
+	n.size = size(n.left) + size(n.right) + 1
+
+	return l
+}
+
+// flipColors swaps the colour of n and both its children
+func flipColors(n *node) {
+	n.color = !n.color
+	n.left.color = !n.left.color
+	n.right.color = !n.right.color
+}
+
+// isRed returns true if the link to n is red. nil links are black
+func isRed(n *node) bool {
+	return n != nil && n.color == red
+}
+
+// size returns the size of n, or 0 for nil
+func size(n *node) int {
+	if n == nil {
+		return 0
+	}
+
+	return n.size
+}
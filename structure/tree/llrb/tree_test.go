@@ -0,0 +1,87 @@
+package llrb
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/noriah/go-code/structure/tree"
+)
+
+// checkInvariants fails the test if the tree is out of order, breaks a
+// red-black rule, or holds a stale size anywhere
+func checkInvariants(t *testing.T, llrb *Tree) {
+	t.Helper()
+
+	if isRed(llrb.root) {
+		t.Fatalf("root %v is red", llrb.root.key)
+	}
+
+	var check func(n *node, lo, hi interface{}) (int, int)
+	check = func(n *node, lo, hi interface{}) (int, int) {
+		if n == nil {
+			return 0, 0
+		}
+
+		if lo != nil && llrb.compare(n.key, lo) <= 0 || hi != nil && llrb.compare(n.key, hi) >= 0 {
+			t.Fatalf("key %v out of order, expected between %v and %v", n.key, lo, hi)
+		}
+
+		if isRed(n.right) {
+			t.Fatalf("key %v has a red right link", n.key)
+		}
+
+		if isRed(n) && isRed(n.left) {
+			t.Fatalf("key %v and its left child are both red", n.key)
+		}
+
+		lb, ls := check(n.left, lo, n.key)
+		rb, rs := check(n.right, n.key, hi)
+
+		if lb != rb {
+			t.Fatalf("key %v: black heights differ, %d left and %d right", n.key, lb, rb)
+		}
+
+		if n.size != ls+rs+1 {
+			t.Fatalf("key %v: expected size %d, got %d", n.key, ls+rs+1, n.size)
+		}
+
+		if !isRed(n) {
+			lb++
+		}
+
+		return lb, n.size
+	}
+
+	check(llrb.root, nil, nil)
+}
+
+// TestInvariants churns the tree and checks its shape as it goes. The
+// shared tests in structure/tree cover what it holds
+func TestInvariants(t *testing.T) {
+	const count = 2000
+
+	llrb := New(tree.Ints)
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 4*count; i++ {
+		if key := rng.Intn(count); rng.Intn(3) == 0 {
+			llrb.Delete(key)
+		} else {
+			llrb.Put(key, i)
+		}
+
+		if i%50 == 0 {
+			checkInvariants(t, llrb)
+		}
+	}
+
+	checkInvariants(t, llrb)
+
+	// Ordered input is the worst case for a plain BST
+	llrb.Clear()
+	for i := 0; i < count; i++ {
+		llrb.Put(i, nil)
+	}
+
+	checkInvariants(t, llrb)
+}
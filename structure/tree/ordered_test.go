@@ -0,0 +1,169 @@
+package tree_test
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/noriah/go-code/structure/tree"
+	"github.com/noriah/go-code/structure/tree/avl"
+	"github.com/noriah/go-code/structure/tree/llrb"
+)
+
+// orderedMap is what the balanced trees have in common
+type orderedMap interface {
+	Size() int
+	Clear()
+	Put(key, value interface{})
+	Get(key interface{}) (interface{}, error)
+	Delete(key interface{}) error
+	Min() (interface{}, interface{}, error)
+	Max() (interface{}, interface{}, error)
+	Range(from, to interface{}, fn tree.Visit)
+	Rank(key interface{}) int
+	Select(i int) (interface{}, interface{}, error)
+}
+
+var implementations = []struct {
+	name string
+	new  func() orderedMap
+}{
+	{"AVL", func() orderedMap { return avl.New(tree.Ints) }},
+	{"LLRB", func() orderedMap { return llrb.New(tree.Ints) }},
+}
+
+func TestRandomized(t *testing.T) {
+	const count = 2000
+
+	for _, impl := range implementations {
+		m := impl.new()
+		expect := map[int]int{}
+		rng := rand.New(rand.NewSource(1))
+
+		for i := 0; i < 4*count; i++ {
+			key := rng.Intn(count)
+
+			if rng.Intn(3) == 0 {
+				err := m.Delete(key)
+				if _, ok := expect[key]; ok != (err == nil) {
+					t.Fatalf("%s: Delete(%d): key present %t, got error %v", impl.name, key, ok, err)
+				}
+
+				delete(expect, key)
+			} else {
+				m.Put(key, i)
+				expect[key] = i
+			}
+		}
+
+		if m.Size() != len(expect) {
+			t.Fatalf("%s: expected size %d, got %d", impl.name, len(expect), m.Size())
+		}
+
+		for key, value := range expect {
+			if got, err := m.Get(key); err != nil || got != value {
+				t.Errorf("%s: Get(%d): expected %d, got %v (%v)", impl.name, key, value, got, err)
+			}
+		}
+
+		m.Clear()
+
+		if m.Size() != 0 {
+			t.Errorf("%s: expected empty tree after Clear, got size %d", impl.name, m.Size())
+		}
+	}
+}
+
+func TestRankSelect(t *testing.T) {
+	for _, impl := range implementations {
+		m := impl.new()
+
+		if _, _, err := m.Select(0); err == nil {
+			t.Errorf("%s: expected error selecting from an empty tree", impl.name)
+		}
+
+		var keys []int
+		for _, key := range rand.New(rand.NewSource(2)).Perm(500) {
+			m.Put(key*2, key)
+			keys = append(keys, key*2)
+		}
+
+		sort.Ints(keys)
+
+		for i, key := range keys {
+			if got := m.Rank(key); got != i {
+				t.Fatalf("%s: Rank(%d): expected %d, got %d", impl.name, key, i, got)
+			}
+
+			// Missing keys rank where they would go
+			if got := m.Rank(key + 1); got != i+1 {
+				t.Fatalf("%s: Rank(%d): expected %d, got %d", impl.name, key+1, i+1, got)
+			}
+
+			if got, value, err := m.Select(i); err != nil || got != key || value != key/2 {
+				t.Fatalf("%s: Select(%d): expected %d, got %v %v (%v)", impl.name, i, key, got, value, err)
+			}
+		}
+
+		if _, _, err := m.Select(len(keys)); err == nil {
+			t.Errorf("%s: expected error selecting past the end", impl.name)
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	tests := []struct {
+		from, to int
+		expect   string
+	}{
+		{25, 65, "[30 40 50 60]"},
+		{30, 60, "[30 40 50 60]"},
+		{-5, 5, "[0]"},
+		{95, 200, "[]"},
+		{60, 30, "[]"},
+	}
+
+	for _, impl := range implementations {
+		m := impl.new()
+
+		if _, _, err := m.Min(); err == nil {
+			t.Errorf("%s: expected error on Min of an empty tree", impl.name)
+		}
+
+		for i := 0; i < 100; i += 10 {
+			m.Put(i, nil)
+		}
+
+		for _, test := range tests {
+			var keys []interface{}
+			m.Range(test.from, test.to, func(key, value interface{}) bool {
+				keys = append(keys, key)
+				return true
+			})
+
+			if got := fmt.Sprint(keys); got != test.expect {
+				t.Errorf("%s: Range(%d, %d): expected %s, got %s", impl.name, test.from, test.to, test.expect, got)
+			}
+		}
+
+		// Stopping early
+		var count int
+		m.Range(0, 90, func(key, value interface{}) bool {
+			count++
+			return count < 3
+		})
+
+		if count != 3 {
+			t.Errorf("%s: expected range to stop after %d keys, got %d", impl.name, 3, count)
+		}
+
+		if key, _, _ := m.Min(); key != 0 {
+			t.Errorf("%s: Min: expected %d, got %v", impl.name, 0, key)
+		}
+
+		if key, _, _ := m.Max(); key != 90 {
+			t.Errorf("%s: Max: expected %d, got %v", impl.name, 90, key)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+// Package tree holds the pieces shared by the tree implementations.
+// Trees keep their keys in order using a Compare function, so any key
+// type works as long as you can say which of two keys comes first.
+package tree
+
+import "strings"
+
+// Compare returns a negative number when a < b, 0 when a == b and a
+// positive number when a > b
+type Compare func(a, b interface{}) int
+
+// Visit is called for each entry during a traversal.
+// Return false to stop the traversal early.
+type Visit func(key, value interface{}) bool
+
+// Ints compares int keys
+func Ints(a, b interface{}) int {
+	var x, y = a.(int), b.(int)
+
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Strings compares string keys
+func Strings(a, b interface{}) int {
+	return strings.Compare(a.(string), b.(string))
+}
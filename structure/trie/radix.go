@@ -0,0 +1,343 @@
+package trie
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// edge is a run of bytes in the radix tree
+type edge struct {
+	prefix   string      // Bytes on the edge from our parent. Empty only for the root
+	children []*edge     // Children sorted by the first byte of their prefix
+	value    interface{} // Value held, when leaf is set
+	leaf     bool        // A key ends here
+}
+
+// Radix implements a Radix Tree, a Trie with single-child chains
+// merged into one node
+type Radix struct {
+	mu    sync.Mutex // Mutex for safe parallel operations
+	root  *edge      // Root node, for the empty prefix
+	count int        // Total number of keys
+}
+
+// NewRadix returns a new Radix Tree
+func NewRadix() *Radix {
+	return &Radix{root: &edge{}}
+}
+
+// Size returns the number of keys in the tree
+func (r *Radix) Size() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.count
+}
+
+// IsEmpty checks for tree emptiness
+func (r *Radix) IsEmpty() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.count == 0
+}
+
+// Clear empties the tree
+func (r *Radix) Clear() {
+	r.mu.Lock()
+
+	r.root = &edge{}
+	r.count = 0
+
+	r.mu.Unlock()
+}
+
+// Insert sets the value for key, adding the key if it is new.
+//
+// Time: O(len(key))
+func (r *Radix) Insert(key string, value interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var e = r.root
+
+	for key != "" {
+		var next = e.child(key[0])
+
+		// Nothing shares our first byte. Hang the rest of the key here
+		if next == nil {
+			next = &edge{prefix: key}
+			e.addChild(next)
+			e, key = next, ""
+			break
+		}
+
+		var common = commonPrefix(next.prefix, key)
+
+		// The key runs off the end of the edge before they differ.
+		// Split the edge where they part ways
+		if common < len(next.prefix) {
+			var split = &edge{prefix: next.prefix[:common], children: []*edge{next}}
+			e.replaceChild(split)
+			next.prefix = next.prefix[common:]
+			next = split
+		}
+
+		e, key = next, key[common:]
+	}
+
+	if !e.leaf {
+		e.leaf = true
+		r.count++
+	}
+
+	e.value = value
+}
+
+// Get returns the value for key.
+// Returns nil and error if the key is not in the tree.
+//
+// Time: O(len(key))
+func (r *Radix) Get(key string) (interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var e, rest = r.find(key)
+	if e == nil || rest != "" || !e.leaf {
+		return nil, errorKeyNotFound
+	}
+
+	return e.value, nil
+}
+
+// Has returns true if key is in the tree
+func (r *Radix) Has(key string) bool {
+	var _, err = r.Get(key)
+
+	return err == nil
+}
+
+// Delete removes key from the tree, merging any edges left with a single
+// child. Returns error if the key is not in the tree.
+//
+// Time: O(len(key))
+func (r *Radix) Delete(key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var parent *edge
+	var e = r.root
+
+	for key != "" {
+		var next = e.child(key[0])
+		if next == nil || !strings.HasPrefix(key, next.prefix) {
+			return errorKeyNotFound
+		}
+
+		parent, e, key = e, next, key[len(next.prefix):]
+	}
+
+	if !e.leaf {
+		return errorKeyNotFound
+	}
+
+	e.leaf = false
+	e.value = nil
+	r.count--
+
+	if e == r.root {
+		return nil
+	}
+
+	// Nothing below us. Drop the edge, which may leave our parent
+	// with one child to merge
+	if len(e.children) == 0 {
+		parent.removeChild(e.prefix[0])
+		e = parent
+	}
+
+	if e != r.root && !e.leaf && len(e.children) == 1 {
+		e.mergeChild()
+	}
+
+	return nil
+}
+
+// LongestPrefix returns the longest key that is a prefix of s, and its value.
+// Returns error if no key is a prefix of s.
+//
+// Time: O(len(s))
+func (r *Radix) LongestPrefix(s string) (key string, value interface{}, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var found = -1
+	var e, depth = r.root, 0
+
+	for {
+		if e.leaf {
+			found, value = depth, e.value
+		}
+
+		if depth == len(s) {
+			break
+		}
+
+		var next = e.child(s[depth])
+		if next == nil || !strings.HasPrefix(s[depth:], next.prefix) {
+			break
+		}
+
+		e, depth = next, depth+len(next.prefix)
+	}
+
+	if found < 0 {
+		return "", nil, errorKeyNotFound
+	}
+
+	return s[:found], value, nil
+}
+
+// WalkPrefix visits every key starting with prefix, in sorted order.
+// The tree is locked for the whole walk. fn must not call back into the tree.
+//
+// Time: O(len(prefix) + m) where m is the size of the subtree walked
+func (r *Radix) WalkPrefix(prefix string, fn Visit) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var e, rest = r.find(prefix)
+	if e == nil {
+		return
+	}
+
+	// prefix may end part way along e. The keys still start with all of e
+	var buf = make([]byte, 0, 64)
+	buf = append(buf, prefix...)
+	buf = append(buf, e.prefix[len(e.prefix)-len(rest):]...)
+
+	e.walk(buf, fn)
+}
+
+// Complete returns up to limit keys starting with prefix, in sorted order.
+// A limit of 0 or less returns them all.
+func (r *Radix) Complete(prefix string, limit int) []string {
+	var keys []string
+
+	r.WalkPrefix(prefix, func(key string, value interface{}) bool {
+		keys = append(keys, key)
+		return limit <= 0 || len(keys) < limit
+	})
+
+	return keys
+}
+
+// Helper Methods
+// These methods are used internally.
+
+// find follows key down the tree. It returns the edge where key runs out,
+// and the part of that edge's prefix past the end of key. Returns nil if
+// no key starts with key.
+func (r *Radix) find(key string) (*edge, string) {
+	var e = r.root
+
+	for key != "" {
+		var next = e.child(key[0])
+		if next == nil {
+			return nil, ""
+		}
+
+		var common = commonPrefix(next.prefix, key)
+
+		switch {
+		// Key ends part way along the edge
+		case common == len(key):
+			return next, next.prefix[common:]
+
+		// They part ways part way along the edge
+		case common < len(next.prefix):
+			return nil, ""
+		}
+
+		e, key = next, key[common:]
+	}
+
+	return e, ""
+}
+
+// walk visits the keys under e, where buf is the key up to and including e.
+// Returns false to stop
+func (e *edge) walk(buf []byte, fn Visit) bool {
+	if e.leaf && !fn(string(buf), e.value) {
+		return false
+	}
+
+	for _, c := range e.children {
+		if !c.walk(append(buf, c.prefix...), fn) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// mergeChild folds e's only child into e
+func (e *edge) mergeChild() {
+	var c = e.children[0]
+
+	e.prefix += c.prefix
+	e.children = c.children
+	e.value = c.value
+	e.leaf = c.leaf
+}
+
+// search returns where a child starting with b is, or would go
+func (e *edge) search(b byte) int {
+	return sort.Search(len(e.children), func(i int) bool {
+		return e.children[i].prefix[0] >= b
+	})
+}
+
+// child returns the child starting with b, or nil
+func (e *edge) child(b byte) *edge {
+	var i = e.search(b)
+	if i < len(e.children) && e.children[i].prefix[0] == b {
+		return e.children[i]
+	}
+
+	return nil
+}
+
+// addChild adds c in order. There must not be a child starting with its byte
+func (e *edge) addChild(c *edge) {
+	var i = e.search(c.prefix[0])
+
+	e.children = append(e.children, nil)
+	copy(e.children[i+1:], e.children[i:])
+	e.children[i] = c
+}
+
+// replaceChild swaps in c for the child starting with the same byte
+func (e *edge) replaceChild(c *edge) {
+	e.children[e.search(c.prefix[0])] = c
+}
+
+// removeChild takes out the child starting with b
+func (e *edge) removeChild(b byte) {
+	var i = e.search(b)
+
+	copy(e.children[i:], e.children[i+1:])
+	e.children[len(e.children)-1] = nil
+	e.children = e.children[:len(e.children)-1]
+}
+
+// commonPrefix returns the length of the prefix a and b share
+func commonPrefix(a, b string) int {
+	var i = 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+
+	return i
+}
@@ -0,0 +1,282 @@
+// Package trie holds implementations for string keyed prefix trees.
+// A trie stores keys by their bytes. Keys that share a prefix share the
+// path for it, so everything under a prefix is one subtree. That makes
+// prefix lookups, longest-prefix matching and autocomplete cheap.
+//
+// Trie keeps one byte per node. Radix squeezes chains of single-child
+// nodes into one node holding a whole string, which saves a lot of
+// memory when keys are long and share few branch points.
+package trie
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// An error to be returned when a key is not in the trie
+var errorKeyNotFound = errors.New("key not found")
+
+// Visit is called for each key during a walk.
+// Return false to stop the walk early.
+type Visit func(key string, value interface{}) bool
+
+// node is a byte in the trie
+type node struct {
+	label    byte        // Byte on the edge from our parent
+	children []*node     // Children sorted by label
+	value    interface{} // Value held, when leaf is set
+	leaf     bool        // A key ends here
+}
+
+// Trie implements a plain Trie with one byte per node
+type Trie struct {
+	mu    sync.Mutex // Mutex for safe parallel operations
+	root  *node      // Root node, for the empty prefix
+	count int        // Total number of keys
+}
+
+// New returns a new Trie
+func New() *Trie {
+	return &Trie{root: &node{}}
+}
+
+// Size returns the number of keys in the trie
+func (t *Trie) Size() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.count
+}
+
+// IsEmpty checks for trie emptiness
+func (t *Trie) IsEmpty() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.count == 0
+}
+
+// Clear empties the trie
+func (t *Trie) Clear() {
+	t.mu.Lock()
+
+	t.root = &node{}
+	t.count = 0
+
+	t.mu.Unlock()
+}
+
+// Insert sets the value for key, adding the key if it is new.
+//
+// Time: O(len(key))
+func (t *Trie) Insert(key string, value interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var n = t.root
+
+	for i := 0; i < len(key); i++ {
+		var next = n.child(key[i])
+
+		if next == nil {
+			next = &node{label: key[i]}
+			n.addChild(next)
+		}
+
+		n = next
+	}
+
+	if !n.leaf {
+		n.leaf = true
+		t.count++
+	}
+
+	n.value = value
+}
+
+// Get returns the value for key.
+// Returns nil and error if the key is not in the trie.
+//
+// Time: O(len(key))
+func (t *Trie) Get(key string) (interface{}, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var n = t.find(key)
+	if n == nil || !n.leaf {
+		return nil, errorKeyNotFound
+	}
+
+	return n.value, nil
+}
+
+// Has returns true if key is in the trie
+func (t *Trie) Has(key string) bool {
+	var _, err = t.Get(key)
+
+	return err == nil
+}
+
+// Delete removes key from the trie, along with any nodes left holding
+// nothing. Returns error if the key is not in the trie.
+//
+// Time: O(len(key))
+func (t *Trie) Delete(key string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Keep the path so we can prune on the way back up
+	var path = make([]*node, 0, len(key)+1)
+	var n = t.root
+
+	for i := 0; n != nil; i++ {
+		path = append(path, n)
+
+		if i == len(key) {
+			break
+		}
+
+		n = n.child(key[i])
+	}
+
+	if n == nil || !n.leaf {
+		return errorKeyNotFound
+	}
+
+	n.leaf = false
+	n.value = nil
+	t.count--
+
+	// Drop nodes with no key and no children, from the bottom up
+	for i := len(path) - 1; i > 0; i-- {
+		if path[i].leaf || len(path[i].children) > 0 {
+			break
+		}
+
+		path[i-1].removeChild(path[i].label)
+	}
+
+	return nil
+}
+
+// LongestPrefix returns the longest key that is a prefix of s, and its value.
+// Returns error if no key is a prefix of s.
+//
+// Time: O(len(s))
+func (t *Trie) LongestPrefix(s string) (key string, value interface{}, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var found = -1
+	var n = t.root
+
+	for i := 0; n != nil; i++ {
+		if n.leaf {
+			found, value = i, n.value
+		}
+
+		if i == len(s) {
+			break
+		}
+
+		n = n.child(s[i])
+	}
+
+	if found < 0 {
+		return "", nil, errorKeyNotFound
+	}
+
+	return s[:found], value, nil
+}
+
+// WalkPrefix visits every key starting with prefix, in sorted order.
+// The trie is locked for the whole walk. fn must not call back into the trie.
+//
+// Time: O(len(prefix) + m) where m is the size of the subtree walked
+func (t *Trie) WalkPrefix(prefix string, fn Visit) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if n := t.find(prefix); n != nil {
+		n.walk([]byte(prefix), fn)
+	}
+}
+
+// Complete returns up to limit keys starting with prefix, in sorted order.
+// A limit of 0 or less returns them all.
+func (t *Trie) Complete(prefix string, limit int) []string {
+	var keys []string
+
+	t.WalkPrefix(prefix, func(key string, value interface{}) bool {
+		keys = append(keys, key)
+		return limit <= 0 || len(keys) < limit
+	})
+
+	return keys
+}
+
+// Helper Methods
+// These methods are used internally.
+
+// find returns the node at the end of key's path, or nil
+func (t *Trie) find(key string) *node {
+	var n = t.root
+
+	for i := 0; n != nil && i < len(key); i++ {
+		n = n.child(key[i])
+	}
+
+	return n
+}
+
+// walk visits the keys under n, where buf is the key up to and including n.
+// Returns false to stop
+func (n *node) walk(buf []byte, fn Visit) bool {
+	if n.leaf && !fn(string(buf), n.value) {
+		return false
+	}
+
+	for _, c := range n.children {
+		if !c.walk(append(buf, c.label), fn) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// search returns where a child labelled b is, or would go
+func (n *node) search(b byte) int {
+	return sort.Search(len(n.children), func(i int) bool {
+		return n.children[i].label >= b
+	})
+}
+
+// child returns the child labelled b, or nil
+func (n *node) child(b byte) *node {
+	var i = n.search(b)
+	if i < len(n.children) && n.children[i].label == b {
+		return n.children[i]
+	}
+
+	return nil
+}
+
+// addChild adds c in label order. There must not be a child with its label
+func (n *node) addChild(c *node) {
+	var i = n.search(c.label)
+
+	n.children = append(n.children, nil)
+	copy(n.children[i+1:], n.children[i:])
+	n.children[i] = c
+}
+
+// removeChild takes out the child labelled b
+func (n *node) removeChild(b byte) {
+	var i = n.search(b)
+
+	copy(n.children[i:], n.children[i+1:])
+	n.children[len(n.children)-1] = nil
+	n.children = n.children[:len(n.children)-1]
+}
@@ -0,0 +1,226 @@
+package trie
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// prefixTree is what both tries have in common
+type prefixTree interface {
+	Size() int
+	IsEmpty() bool
+	Clear()
+	Insert(key string, value interface{})
+	Get(key string) (interface{}, error)
+	Has(key string) bool
+	Delete(key string) error
+	LongestPrefix(s string) (string, interface{}, error)
+	WalkPrefix(prefix string, fn Visit)
+	Complete(prefix string, limit int) []string
+}
+
+var implementations = []struct {
+	name  string
+	new   func() prefixTree
+	check func(t *testing.T, p prefixTree)
+}{
+	{"Trie", func() prefixTree { return New() }, checkTrie},
+	{"Radix", func() prefixTree { return NewRadix() }, checkRadix},
+}
+
+// checkTrie fails the test if a node other than the root holds no key
+// and has no children, or if children are out of order
+func checkTrie(t *testing.T, p prefixTree) {
+	t.Helper()
+
+	var check func(n *node, root bool)
+	check = func(n *node, root bool) {
+		if !root && !n.leaf && len(n.children) == 0 {
+			t.Fatalf("dead node %q left in the trie", n.label)
+		}
+
+		for i, c := range n.children {
+			if i > 0 && n.children[i-1].label >= c.label {
+				t.Fatalf("children %q and %q out of order", n.children[i-1].label, c.label)
+			}
+
+			check(c, false)
+		}
+	}
+
+	check(p.(*Trie).root, true)
+}
+
+// checkRadix fails the test if an edge other than the root is empty, holds
+// no key with fewer than two children, or if children are out of order
+func checkRadix(t *testing.T, p prefixTree) {
+	t.Helper()
+
+	var check func(e *edge, root bool)
+	check = func(e *edge, root bool) {
+		if !root && e.prefix == "" {
+			t.Fatalf("empty edge below the root")
+		}
+
+		if !root && !e.leaf && len(e.children) < 2 {
+			t.Fatalf("edge %q holds no key and has %d children", e.prefix, len(e.children))
+		}
+
+		for i, c := range e.children {
+			if i > 0 && e.children[i-1].prefix[0] >= c.prefix[0] {
+				t.Fatalf("children %q and %q out of order", e.children[i-1].prefix, c.prefix)
+			}
+
+			check(c, false)
+		}
+	}
+
+	check(p.(*Radix).root, true)
+}
+
+func TestRandomized(t *testing.T) {
+	for _, impl := range implementations {
+		p := impl.new()
+		expect := map[string]int{}
+		rng := rand.New(rand.NewSource(1))
+
+		// Short keys over a small alphabet share lots of prefixes
+		randomKey := func() string {
+			b := make([]byte, rng.Intn(6))
+			for i := range b {
+				b[i] = "abc"[rng.Intn(3)]
+			}
+			return string(b)
+		}
+
+		for i := 0; i < 5000; i++ {
+			key := randomKey()
+
+			if rng.Intn(3) == 0 {
+				err := p.Delete(key)
+				if _, ok := expect[key]; ok != (err == nil) {
+					t.Fatalf("%s: Delete(%q): key present %t, got error %v", impl.name, key, ok, err)
+				}
+
+				delete(expect, key)
+			} else {
+				p.Insert(key, i)
+				expect[key] = i
+			}
+
+			impl.check(t, p)
+		}
+
+		if p.Size() != len(expect) {
+			t.Fatalf("%s: expected size %d, got %d", impl.name, len(expect), p.Size())
+		}
+
+		var sorted []string
+		for key, value := range expect {
+			sorted = append(sorted, key)
+
+			if got, err := p.Get(key); err != nil || got != value {
+				t.Errorf("%s: Get(%q): expected %d, got %v (%v)", impl.name, key, value, got, err)
+			}
+		}
+
+		sort.Strings(sorted)
+
+		if got := p.Complete("", 0); fmt.Sprint(got) != fmt.Sprint(sorted) {
+			t.Errorf("%s: expected keys %v, got %v", impl.name, sorted, got)
+		}
+
+		// Every prefix walk matches a filter over the sorted keys
+		for i := 0; i < 100; i++ {
+			prefix := randomKey()
+
+			var want []string
+			for _, key := range sorted {
+				if strings.HasPrefix(key, prefix) {
+					want = append(want, key)
+				}
+			}
+
+			if got := p.Complete(prefix, 0); fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Fatalf("%s: Complete(%q): expected %v, got %v", impl.name, prefix, want, got)
+			}
+		}
+
+		p.Clear()
+		if !p.IsEmpty() || p.Has("") {
+			t.Errorf("%s: expected empty trie after Clear", impl.name)
+		}
+	}
+}
+
+func TestLongestPrefix(t *testing.T) {
+	for _, impl := range implementations {
+		p := impl.new()
+
+		if _, _, err := p.LongestPrefix("/api"); err != errorKeyNotFound {
+			t.Errorf("%s: expected %v, got %v", impl.name, errorKeyNotFound, err)
+		}
+
+		for _, route := range []string{"/", "/api", "/api/users", "/api/users/admin", "/static"} {
+			p.Insert(route, route)
+		}
+
+		tests := map[string]string{
+			"/":                  "/",
+			"/index.html":        "/",
+			"/api":               "/api",
+			"/apiary":            "/api",
+			"/api/users/42":      "/api/users",
+			"/api/users/admin/x": "/api/users/admin",
+			"/stat":              "/",
+		}
+
+		for s, expect := range tests {
+			key, value, err := p.LongestPrefix(s)
+			if err != nil || key != expect || value != expect {
+				t.Errorf("%s: LongestPrefix(%q): expected %q, got %q %v (%v)", impl.name, s, expect, key, value, err)
+			}
+		}
+
+		if _, _, err := p.LongestPrefix("api"); err != errorKeyNotFound {
+			t.Errorf("%s: expected %v, got %v", impl.name, errorKeyNotFound, err)
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	for _, impl := range implementations {
+		p := impl.new()
+
+		for _, command := range []string{"status", "stash", "stage", "show", "switch", "commit"} {
+			p.Insert(command, nil)
+		}
+
+		tests := []struct {
+			prefix string
+			limit  int
+			expect string
+		}{
+			{"st", 0, "[stage stash status]"},
+			{"sta", 2, "[stage stash]"},
+			{"s", 0, "[show stage stash status switch]"},
+			{"sw", 0, "[switch]"},
+			{"switch", 0, "[switch]"},
+			{"switches", 0, "[]"},
+			{"x", 0, "[]"},
+		}
+
+		for _, test := range tests {
+			if got := fmt.Sprint(p.Complete(test.prefix, test.limit)); got != test.expect {
+				t.Errorf("%s: Complete(%q, %d): expected %s, got %s", impl.name, test.prefix, test.limit, test.expect, got)
+			}
+		}
+
+		if err := p.Delete("st"); err != errorKeyNotFound {
+			t.Errorf("%s: Delete of a bare prefix: expected %v, got %v", impl.name, errorKeyNotFound, err)
+		}
+	}
+}